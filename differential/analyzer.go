@@ -0,0 +1,81 @@
+// Package differential реализует дифференциальный криптоанализ DES с уменьшенным числом раундов:
+// таблицы распределения разностей S-блоков, поиск характеристик и атаку с выбранными открытыми
+// текстами, восстанавливающую биты подключа последнего раунда 4- и 6-раундового DES
+package differential
+
+import "IB3/myDes"
+
+// Analyzer хранит таблицы варианта DES в виде, удобном для анализа разностей
+type Analyzer struct {
+	variant *myDes.Variant
+	ip, fp  []int // Начальная и конечная перестановки
+	e       []int // Таблица расширения
+	p, pInv []int // Перестановка P и обратная к ней
+	pc1     []int
+	pc2     []int
+	spin    [16]int // Суммарный сдвиг половин ключа к каждому раунду
+	sBox    [8][64]uint8
+	ddt     [8]DDT
+}
+
+// NewAnalyzer подготавливает анализ варианта DES (nil - таблицы стандарта)
+func NewAnalyzer(variant *myDes.Variant) *Analyzer {
+	t := variant.Tables()
+	a := &Analyzer{
+		variant: variant,
+		ip:      t.IP,
+		fp:      myDes.InversePermutation(t.IP),
+		e:       t.E,
+		p:       t.P,
+		pInv:    myDes.InversePermutation(t.P),
+		pc1:     t.PC1,
+		pc2:     t.PC2,
+		sBox:    variant.SBoxLookup(),
+	}
+
+	total := 0
+	for i, shift := range t.Shifts {
+		total += shift
+		a.spin[i] = total % 28
+	}
+
+	for box := range a.sBox {
+		a.ddt[box] = a.newDDT(box)
+	}
+	return a
+}
+
+// expand расширяет 32-битную половину блока до 48 бит
+func (a *Analyzer) expand(half uint32) uint64 {
+	return myDes.Permute(uint64(half), a.e, 32)
+}
+
+// sBoxInput возвращает 6-битный вход S-блока box (с нуля) из 48-битного блока
+func sBoxInput(block48 uint64, box int) uint8 {
+	return uint8(block48>>(42-6*box)) & 0x3f
+}
+
+// sBoxOutput возвращает 4-битный выход S-блока box из значения функции F, восстанавливая его по P^-1
+func (a *Analyzer) sBoxOutput(f uint32, box int) uint8 {
+	return uint8(myDes.Permute(uint64(f), a.pInv, 32)>>(28-4*box)) & 0xf
+}
+
+// activeBoxes отмечает S-блоки, на вход которых попадает ненулевая разность при разности diff правой половины
+func (a *Analyzer) activeBoxes(diff uint32) [8]bool {
+	var active [8]bool
+	expanded := a.expand(diff)
+	for box := range active {
+		active[box] = sBoxInput(expanded, box) != 0
+	}
+	return active
+}
+
+// initialState переводит открытый текст в состояние L0 || R0 после начальной перестановки
+func (a *Analyzer) initialState(plaintext uint64) uint64 {
+	return myDes.Permute(plaintext, a.ip, 64)
+}
+
+// plaintext переводит состояние L0 || R0 обратно в открытый текст
+func (a *Analyzer) plaintext(state uint64) uint64 {
+	return myDes.Permute(state, a.fp, 64)
+}
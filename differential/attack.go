@@ -0,0 +1,221 @@
+package differential
+
+import (
+	"IB3/myDes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+)
+
+var (
+	// ErrAttackRounds возвращается при атаке на неподдерживаемое число раундов
+	ErrAttackRounds = errors.New("differential: атака поддерживает 4 и 6 раундов")
+	// ErrKeyNotFound возвращается, если ни один ключ не согласуется с восстановленными битами подключа
+	ErrKeyNotFound = errors.New("differential: ключ не найден, восстановленные биты подключа неверны")
+	// ErrTooManyUnknownBits возвращается, если для перебора остается слишком много бит ключа
+	ErrTooManyUnknownBits = errors.New("differential: слишком много неизвестных бит ключа для перебора")
+)
+
+// maxUnknownBits - наибольшее число бит ключа, перебираемых в RecoverKey
+const maxUnknownBits = 24
+
+// Oracle шифрует выбранный открытый текст атакуемым шифром
+type Oracle func(plaintext uint64) uint64
+
+// NewOracle возвращает оракул, шифрующий варианта DES (nil - стандарт) с rounds раундами на ключе key
+func NewOracle(variant *myDes.Variant, key []byte, rounds int) (Oracle, error) {
+	b, err := variant.NewCipher(key, rounds)
+	if err != nil {
+		return nil, err
+	}
+	return func(plaintext uint64) uint64 {
+		var buf [myDes.BlockSize]byte
+		binary.BigEndian.PutUint64(buf[:], plaintext)
+		b.Encrypt(buf[:], buf[:])
+		return binary.BigEndian.Uint64(buf[:])
+	}, nil
+}
+
+// BoxResult - результат подсчета голосов за 6 бит подключа одного S-блока
+type BoxResult struct {
+	Box    int   // Номер S-блока, начиная с нуля
+	Key    uint8 // 6 бит подключа с наибольшим числом голосов
+	Votes  int   // Число голосов за Key
+	Second int   // Число голосов за следующий по популярности вариант: чем больше разрыв, тем надежнее результат
+}
+
+// Result - результат атаки на последний подключ
+type Result struct {
+	Rounds          int              // Число раундов атакуемого шифра
+	Characteristics []Characteristic // Использованные характеристики
+	Pairs           int              // Число пар выбранных открытых текстов
+	Filtered        int              // Число пар, прошедших фильтр разностей
+	Boxes           []BoxResult      // Результаты по S-блокам, подключи которых восстановлены
+	SubKey          uint64           // Восстановленные биты подключа последнего раунда
+	SubKeyMask      uint64           // Маска восстановленных бит (по 6 бит на S-блок)
+}
+
+// Attack восстанавливает биты подключа последнего раунда DES с rounds раундами (4 или 6) атакой
+// с выбранными открытыми текстами. Для каждой выбранной характеристики шифруется pairs пар
+// открытых текстов с разностью характеристики. Если пара следует характеристике, выходная разность
+// функции F последнего раунда на неактивных в раунде rounds-2 S-блоках равна R' XOR L'
+// характеристики, а входы этих S-блоков известны по шифротексту: каждый вариант 6 бит подключа,
+// объясняющий разности, получает голос. Пары, разности которых невозможны по DDT, отбрасываются
+func (a *Analyzer) Attack(rounds, pairs int, oracle Oracle, rnd *rand.Rand) (*Result, error) {
+	if rounds != 4 && rounds != 6 {
+		return nil, ErrAttackRounds
+	}
+	characteristics, err := a.Characteristics(rounds - 3)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Rounds: rounds, Characteristics: a.selectCharacteristics(characteristics)}
+	var (
+		votes   [8][64]int
+		covered [8]bool
+	)
+	for _, c := range result.Characteristics {
+		targets := a.TargetBoxes(c)
+		for _, box := range targets {
+			covered[box] = true
+		}
+
+		for i := 0; i < pairs; i++ {
+			state := rnd.Uint64()
+			first := a.initialState(oracle(a.plaintext(state)))
+			second := a.initialState(oracle(a.plaintext(state ^ (uint64(c.InL)<<32 | uint64(c.InR)))))
+			result.Pairs++
+
+			// После конечной перестановки шифротекст - это R || L последнего раунда, L - вход функции F
+			left1, left2 := a.expand(uint32(first)), a.expand(uint32(second))
+			outDiff := uint32(first>>32) ^ uint32(second>>32) ^ c.OutL
+
+			// Фильтр: разность на каждом целевом S-блоке должна быть возможной
+			possible := true
+			for _, box := range targets {
+				in := sBoxInput(left1, box) ^ sBoxInput(left2, box)
+				if a.ddt[box][in][a.sBoxOutput(outDiff, box)] == 0 {
+					possible = false
+					break
+				}
+			}
+			if !possible {
+				continue
+			}
+			result.Filtered++
+
+			for _, box := range targets {
+				x1, x2 := sBoxInput(left1, box), sBoxInput(left2, box)
+				out := a.sBoxOutput(outDiff, box)
+				for k := uint8(0); k < 64; k++ {
+					if a.sBox[box][x1^k]^a.sBox[box][x2^k] == out {
+						votes[box][k]++
+					}
+				}
+			}
+		}
+	}
+
+	for box := range covered {
+		if !covered[box] {
+			continue
+		}
+		r := BoxResult{Box: box}
+		for k, v := range votes[box] {
+			if v > r.Votes {
+				r.Second, r.Votes, r.Key = r.Votes, v, uint8(k)
+			} else if v > r.Second {
+				r.Second = v
+			}
+		}
+		result.Boxes = append(result.Boxes, r)
+		result.SubKey |= uint64(r.Key) << (42 - 6*box)
+		result.SubKeyMask |= uint64(0x3f) << (42 - 6*box)
+	}
+	return result, nil
+}
+
+// selectCharacteristics выбирает из самых вероятных характеристик те, что добавляют новые целевые S-блоки
+func (a *Analyzer) selectCharacteristics(characteristics []Characteristic) []Characteristic {
+	var (
+		selected []Characteristic
+		covered  [8]bool
+	)
+	for _, c := range characteristics {
+		if c.Probability < characteristics[0].Probability {
+			break
+		}
+		added := false
+		for _, box := range a.TargetBoxes(c) {
+			if !covered[box] {
+				covered[box], added = true, true
+			}
+		}
+		if added {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
+
+// RecoverKey восстанавливает полный ключ: биты ключа, которые определяют восстановленные биты подключа
+// последнего раунда, берутся из результата атаки, остальные перебираются и проверяются на известных
+// парах открытый текст - шифротекст. Возвращает ключ с исправленной четностью и число проверенных ключей
+func (a *Analyzer) RecoverKey(result *Result, known [][2]uint64) ([]byte, int, error) {
+	// Для каждого бита подключа находим бит ключа, из которого он получен PC-1, сдвигами и PC-2
+	var key, keyMask uint64
+	spin := a.spin[(result.Rounds-1)%16]
+	for i, cd := range a.pc2 {
+		if result.SubKeyMask>>(47-i)&1 == 0 {
+			continue
+		}
+		// Позиция в половине C или D до сдвигов
+		half, position := 0, cd-1
+		if position >= 28 {
+			half, position = 28, position-28
+		}
+		bit := a.pc1[half+(position+spin)%28] // Номер бита ключа с 1, начиная со старшего
+		key |= (result.SubKey >> (47 - i) & 1) << (64 - bit)
+		keyMask |= 1 << (64 - bit)
+	}
+
+	// Неизвестные биты ключа - биты PC-1, не определенные подключом
+	var unknown []uint64
+	for _, bit := range a.pc1 {
+		if keyMask>>(64-bit)&1 == 0 {
+			unknown = append(unknown, 1<<(64-bit))
+		}
+	}
+	if len(unknown) > maxUnknownBits {
+		return nil, 0, ErrTooManyUnknownBits
+	}
+
+	var buf [myDes.BlockSize]byte
+	for candidate := 0; candidate < 1<<len(unknown); candidate++ {
+		k := key
+		for i, bit := range unknown {
+			if candidate&(1<<i) != 0 {
+				k |= bit
+			}
+		}
+		binary.BigEndian.PutUint64(buf[:], k)
+		keyBytes := myDes.FixParity(buf[:])
+
+		oracle, err := NewOracle(a.variant, keyBytes, result.Rounds)
+		if err != nil {
+			return nil, candidate, err
+		}
+		matches := true
+		for _, pair := range known {
+			if oracle(pair[0]) != pair[1] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return keyBytes, candidate + 1, nil
+		}
+	}
+	return nil, 1 << len(unknown), ErrKeyNotFound
+}
@@ -0,0 +1,124 @@
+package differential
+
+import (
+	"IB3/myDes"
+	"errors"
+	"sort"
+)
+
+// ErrCharacteristicRounds возвращается при запросе характеристик с неподдерживаемым числом раундов
+var ErrCharacteristicRounds = errors.New("differential: поддерживаются характеристики на 1 и 3 раунда")
+
+// Characteristic - дифференциальная характеристика: разность состояния L || R после начальной
+// перестановки InL || InR через Rounds раундов переходит в OutL || OutR с вероятностью Probability
+type Characteristic struct {
+	Rounds      int
+	InL, InR    uint32
+	OutL, OutR  uint32
+	Probability float64
+}
+
+// Characteristics находит характеристики на rounds раундов, пригодные для атаки на rounds+3 раунда,
+// упорядоченные по убыванию вероятности, а при равной вероятности - по числу S-блоков, неактивных
+// в следующем раунде (именно их подключи восстанавливает атака).
+//
+// На 1 раунд берутся характеристики (X, 0) -> (0, X) с вероятностью 1, где X активирует один S-блок.
+// На 3 раунда - характеристики (F, X) -> (X, F): X переходит в F в первом раунде с вероятностью p,
+// во втором раунде разность нулевая, в третьем снова X -> F; итоговая вероятность p^2
+func (a *Analyzer) Characteristics(rounds int) ([]Characteristic, error) {
+	var result []Characteristic
+	switch rounds {
+	case 1:
+		for _, in := range a.singleBoxDifferences() {
+			result = append(result, Characteristic{Rounds: 1, InL: in, OutR: in, Probability: 1})
+		}
+	case 3:
+		for _, in := range a.singleBoxDifferences() {
+			box, input := a.singleActiveBox(in)
+			for out := uint8(0); out < 16; out++ {
+				count := a.ddt[box][input][out]
+				if count == 0 {
+					continue
+				}
+				f := uint32(myDes.Permute(uint64(out)<<(28-4*box), a.p, 32))
+				p := float64(count) / 64
+				result = append(result, Characteristic{Rounds: 3, InL: f, InR: in, OutL: in, OutR: f, Probability: p * p})
+			}
+		}
+	default:
+		return nil, ErrCharacteristicRounds
+	}
+
+	// Характеристики, после которых в следующем раунде активны все S-блоки, для атаки бесполезны
+	useful := result[:0]
+	for _, c := range result {
+		if a.inactiveCount(c) > 0 {
+			useful = append(useful, c)
+		}
+	}
+	sort.SliceStable(useful, func(i, j int) bool {
+		if useful[i].Probability != useful[j].Probability {
+			return useful[i].Probability > useful[j].Probability
+		}
+		return a.inactiveCount(useful[i]) > a.inactiveCount(useful[j])
+	})
+	return useful, nil
+}
+
+// TargetBoxes возвращает S-блоки, неактивные в раунде после характеристики: для них выходная разность
+// функции F последнего раунда известна, и по ним восстанавливаются биты последнего подключа
+func (a *Analyzer) TargetBoxes(c Characteristic) []int {
+	var boxes []int
+	for box, active := range a.activeBoxes(c.OutR) {
+		if !active {
+			boxes = append(boxes, box)
+		}
+	}
+	return boxes
+}
+
+// inactiveCount возвращает число S-блоков, неактивных в раунде после характеристики
+func (a *Analyzer) inactiveCount(c Characteristic) int {
+	return len(a.TargetBoxes(c))
+}
+
+// singleBoxDifferences перечисляет ненулевые разности правой половины, активирующие ровно один S-блок
+func (a *Analyzer) singleBoxDifferences() []uint32 {
+	var result []uint32
+	for box := 0; box < 8; box++ {
+		// Биты, которые попадают только в этот S-блок
+		var bits []uint32
+		for bit := 1; bit <= 32; bit++ {
+			if b, _ := a.singleActiveBox(1 << (32 - bit)); b == box {
+				bits = append(bits, 1<<(32-bit))
+			}
+		}
+		// Все непустые сочетания этих битов
+		for subset := 1; subset < 1<<len(bits); subset++ {
+			var diff uint32
+			for i, bit := range bits {
+				if subset&(1<<i) != 0 {
+					diff |= bit
+				}
+			}
+			result = append(result, diff)
+		}
+	}
+	return result
+}
+
+// singleActiveBox возвращает единственный S-блок, активируемый разностью diff, и его входную разность.
+// Если активных S-блоков нет или их несколько, возвращается -1
+func (a *Analyzer) singleActiveBox(diff uint32) (box int, input uint8) {
+	box = -1
+	expanded := a.expand(diff)
+	for b := 0; b < 8; b++ {
+		if in := sBoxInput(expanded, b); in != 0 {
+			if box != -1 {
+				return -1, 0
+			}
+			box, input = b, in
+		}
+	}
+	return box, input
+}
@@ -0,0 +1,38 @@
+package differential
+
+// DDT - таблица распределения разностей S-блока: DDT[a][b] - число 6-битных входов x,
+// для которых S(x) XOR S(x XOR a) = b
+type DDT [64][16]int
+
+// newDDT строит таблицу распределения разностей S-блока box
+func (a *Analyzer) newDDT(box int) DDT {
+	var t DDT
+	for in := 0; in < 64; in++ {
+		for x := 0; x < 64; x++ {
+			t[in][a.sBox[box][x]^a.sBox[box][x^in]]++
+		}
+	}
+	return t
+}
+
+// DDT возвращает таблицу распределения разностей S-блока box (с нуля)
+func (a *Analyzer) DDT(box int) DDT {
+	return a.ddt[box]
+}
+
+// Probability возвращает вероятность того, что входная разность in перейдет в выходную out
+func (t *DDT) Probability(in, out uint8) float64 {
+	return float64(t[in][out]) / 64
+}
+
+// Max возвращает наибольшее значение таблицы при ненулевой входной разности и соответствующие разности
+func (t *DDT) Max() (count int, in, out uint8) {
+	for a := 1; a < 64; a++ {
+		for b := 0; b < 16; b++ {
+			if t[a][b] > count {
+				count, in, out = t[a][b], uint8(a), uint8(b)
+			}
+		}
+	}
+	return count, in, out
+}
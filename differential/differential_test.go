@@ -0,0 +1,134 @@
+package differential
+
+import (
+	"IB3/myDes"
+	"bytes"
+	"errors"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+// TestDDT проверяет свойства таблиц распределения разностей S-блоков DES
+func TestDDT(t *testing.T) {
+	a := NewAnalyzer(nil)
+	for box := 0; box < 8; box++ {
+		ddt := a.DDT(box)
+		if ddt[0][0] != 64 {
+			t.Errorf("S%d: нулевая разность дает нулевую с частотой %d", box+1, ddt[0][0])
+		}
+		for in := 1; in < 64; in++ {
+			sum := 0
+			for out, count := range ddt[in] {
+				sum += count
+				if count%2 != 0 {
+					t.Errorf("S%d: DDT[%02x][%x] = %d нечетно", box+1, in, out, count)
+				}
+			}
+			if sum != 64 {
+				t.Errorf("S%d: строка %02x: сумма %d", box+1, in, sum)
+			}
+		}
+
+		// Критерий проектирования DES: изменение одного входного бита меняет не менее двух выходных
+		for bit := 0; bit < 6; bit++ {
+			for out, count := range ddt[1<<bit] {
+				if count > 0 && bits.OnesCount8(uint8(out)) < 2 {
+					t.Errorf("S%d: разность %02x переходит в %x", box+1, 1<<bit, out)
+				}
+			}
+		}
+	}
+
+	// Самый вероятный переход S1 из работы Бихама и Шамира: 34 -> 2 с вероятностью 16/64
+	ddt := a.DDT(0)
+	if count, in, out := ddt.Max(); count != 16 || in != 0x34 || out != 0x2 {
+		t.Errorf("S1: максимум %d для %02x -> %x", count, in, out)
+	}
+	if p := ddt.Probability(0x34, 0x2); p != 0.25 {
+		t.Errorf("вероятность %v", p)
+	}
+}
+
+// TestCharacteristics проверяет, что лучшие характеристики на 3 раунда совпадают с характеристиками
+// атаки на 6-раундовый DES из работы Бихама и Шамира
+func TestCharacteristics(t *testing.T) {
+	a := NewAnalyzer(nil)
+	found, err := a.Characteristics(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Characteristic{
+		{Rounds: 3, InL: 0x40080000, InR: 0x04000000, OutL: 0x04000000, OutR: 0x40080000, Probability: 1.0 / 16},
+		{Rounds: 3, InL: 0x00200008, InR: 0x00000400, OutL: 0x00000400, OutR: 0x00200008, Probability: 1.0 / 16},
+	}
+	for i, c := range want {
+		if found[i] != c {
+			t.Errorf("характеристика %d: %+v, ожидалось %+v", i, found[i], c)
+		}
+	}
+	if boxes := a.TargetBoxes(found[0]); len(boxes) != 5 {
+		t.Errorf("целевые S-блоки %v", boxes)
+	}
+
+	oneRound, err := a.Characteristics(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range oneRound {
+		if c.Probability != 1 || c.InR != 0 || c.OutL != 0 || c.InL != c.OutR || len(a.TargetBoxes(c)) != 7 {
+			t.Errorf("характеристика на 1 раунд %+v", c)
+		}
+	}
+
+	if _, err := a.Characteristics(2); !errors.Is(err, ErrCharacteristicRounds) {
+		t.Errorf("2 раунда: %v", err)
+	}
+}
+
+// TestAttack восстанавливает подключ последнего раунда и полный ключ 4- и 6-раундового DES
+func TestAttack(t *testing.T) {
+	a := NewAnalyzer(nil)
+	key := myDes.FixParity([]byte("Diff_Key"))
+	schedule, err := myDes.TraceKeySchedule(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		rounds, pairs, boxes int
+	}{
+		{4, 16, 8},
+		{6, 240, 7},
+	} {
+		oracle, err := NewOracle(nil, key, tt.rounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := a.Attack(tt.rounds, tt.pairs, oracle, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Boxes) != tt.boxes || result.Pairs != tt.pairs*len(result.Characteristics) {
+			t.Errorf("%d раундов: %d S-блоков, %d пар", tt.rounds, len(result.Boxes), result.Pairs)
+		}
+
+		subKey := schedule.Rounds[tt.rounds-1].SubKey
+		if result.SubKey != subKey&result.SubKeyMask {
+			t.Errorf("%d раундов: подключ %012x, ожидалось %012x", tt.rounds, result.SubKey, subKey&result.SubKeyMask)
+		}
+
+		known := [][2]uint64{{0x0123456789ABCDEF, oracle(0x0123456789ABCDEF)}, {0, oracle(0)}}
+		recovered, _, err := a.RecoverKey(result, known)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, key) {
+			t.Errorf("%d раундов: ключ %x, ожидалось %x", tt.rounds, recovered, key)
+		}
+	}
+
+	if _, err := a.Attack(5, 10, nil, rand.New(rand.NewSource(1))); !errors.Is(err, ErrAttackRounds) {
+		t.Errorf("5 раундов: %v", err)
+	}
+}
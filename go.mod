@@ -2,4 +2,7 @@ module IB3
 
 go 1.21
 
-require github.com/gorilla/mux v1.8.1 // indirect
+require (
+	github.com/gorilla/mux v1.8.1
+	golang.org/x/crypto v0.31.0
+)
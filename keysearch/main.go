@@ -0,0 +1,134 @@
+// Команда keysearch показывает, почему 56-битного ключа DES мало: она шифрует известный открытый текст
+// случайным ключом, скрывает bits бит ключа и находит их перебором в нескольких горутинах, а по
+// измеренной скорости оценивает время перебора всего пространства ключей. Ctrl+C прерывает перебор.
+//
+//	go run ./keysearch -bits 28 -workers 8
+package main
+
+import (
+	"IB3/myDes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	bits := flag.Int("bits", 24, "number of unknown key bits to search (1-56, parity bits excluded)")
+	keyHex := flag.String("key", "", "secret key as 16 hex digits (random if empty)")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for the key, plaintext and mask")
+	workers := flag.Int("workers", 0, "number of search goroutines (0 - one per CPU)")
+	rounds := flag.Int("rounds", 0, "number of DES rounds (0 - standard 16)")
+	complement := flag.Bool("complement", true, "also encrypt the complemented plaintext; this halves the number of candidates only with -bits 56 and has no effect otherwise")
+	timeout := flag.Duration("timeout", 0, "stop the search after this duration (0 - no limit)")
+	flag.Parse()
+
+	if *bits < 1 || *bits > 56 {
+		log.Fatal("bits must be between 1 and 56")
+	}
+	rnd := rand.New(rand.NewSource(*seed))
+
+	key := make([]byte, myDes.BlockSize)
+	if *keyHex == "" {
+		binary.BigEndian.PutUint64(key, rnd.Uint64())
+	} else {
+		decoded, err := hex.DecodeString(*keyHex)
+		if err != nil || len(decoded) != myDes.BlockSize {
+			log.Fatalf("key must be exactly %d hex digits", 2*myDes.BlockSize)
+		}
+		key = decoded
+	}
+	key = myDes.FixParity(key)
+
+	// Маска из случайных бит ключа; младший бит каждого байта - бит четности, он не перебирается
+	mask := make([]byte, myDes.BlockSize)
+	for _, i := range rnd.Perm(56)[:*bits] {
+		mask[i/7] |= 0x80 >> (i % 7)
+	}
+
+	if *rounds == 0 {
+		*rounds = 16
+	}
+	d := myDes.NewMyDES("", myDes.WithWorkers(*workers), myDes.WithRounds(*rounds))
+	b, err := myDes.NewReducedCipher(key, *rounds)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plain, cipher := make([]byte, myDes.BlockSize), make([]byte, myDes.BlockSize)
+	binary.BigEndian.PutUint64(plain, rnd.Uint64())
+	b.Encrypt(cipher, plain)
+
+	search := myDes.KeySearch{Plaintext: plain, Ciphertext: cipher, Key: key, Mask: mask}
+	fmt.Printf("Known pair: %X -> %X\n", plain, cipher)
+	if *complement {
+		complemented := make([]byte, myDes.BlockSize)
+		for i := range plain {
+			complemented[i] = ^plain[i]
+		}
+		search.ComplementCiphertext = make([]byte, myDes.BlockSize)
+		b.Encrypt(search.ComplementCiphertext, complemented)
+		fmt.Printf("Complement pair: %X -> %X\n", complemented, search.ComplementCiphertext)
+		// Дополнение ключа меняет и известные биты, поэтому перебор сокращается только без известных бит
+		if *bits < 56 {
+			fmt.Println("The complement pair halves the search only with -bits 56; with known key bits it is not used")
+		}
+	}
+	fmt.Printf("Searching %d unknown key bits, mask %X\n", *bits, mask)
+
+	search.Progress = func(p myDes.SearchProgress) {
+		fmt.Fprintf(os.Stderr, "\r%6.2f%%  %d of %d candidates  %.0f keys/s  %s left   ",
+			100*float64(p.Tested)/float64(p.Total), p.Tested, p.Total, p.Rate(), p.Remaining().Round(time.Second))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	result, err := d.SearchKey(ctx, search)
+	fmt.Fprintln(os.Stderr)
+	if result == nil {
+		log.Fatal(err)
+	}
+
+	rate := float64(result.Tested) / result.Elapsed.Seconds()
+	fmt.Printf("Tested %d of %d candidates (%d keys covered) in %s, %.0f keys/s\n",
+		result.Tested, result.Total, result.Space, result.Elapsed.Round(time.Millisecond), rate)
+	switch {
+	case err == nil:
+		how := "directly"
+		if result.Complement {
+			how = "as the complement of a candidate"
+		}
+		fmt.Printf("Key found %s: %X (actual %X)\n", how, result.Key, key)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		fmt.Println("Search interrupted")
+	default:
+		fmt.Println(err)
+	}
+
+	// Оценка полного перебора: при маске на все 56 бит с парой для дополнения достаточно половины ключей
+	full := float64(uint64(1) << 56)
+	if *complement {
+		full /= 2
+	}
+	if rate > 0 {
+		seconds := full / rate
+		fmt.Printf("At this rate the whole 56-bit key space takes %.1f years (%.0f days)\n",
+			seconds/(365.25*24*3600), seconds/(24*3600))
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,89 @@
+// Package linear реализует линейный криптоанализ DES по Мацуи: таблицы линейных аппроксимаций
+// S-блоков, поиск лучших линейных аппроксимаций на несколько раундов и алгоритмы 1 и 2,
+// восстанавливающие биты ключа по известным открытым текстам
+package linear
+
+import (
+	"IB3/myDes"
+	"math/bits"
+)
+
+// Analyzer хранит таблицы варианта DES в виде, удобном для линейного анализа
+type Analyzer struct {
+	variant *myDes.Variant
+	ip      []int // Начальная перестановка
+	e       []int // Таблица расширения
+	p, pInv []int // Перестановка P и обратная к ней
+	sBox    [8][64]uint8
+	lat     [8]LAT
+
+	// Раундовые аппроксимации с одним активным S-блоком по убыванию модуля корреляции
+	candidates []Round
+	// Те же аппроксимации, сгруппированные по маске выхода функции F
+	byOutput map[uint32][]Round
+	// bounds[r] - наибольший модуль корреляции аппроксимации на r раундов, найденный ранее
+	bounds []float64
+}
+
+// NewAnalyzer подготавливает анализ варианта DES (nil - таблицы стандарта)
+func NewAnalyzer(variant *myDes.Variant) *Analyzer {
+	t := variant.Tables()
+	a := &Analyzer{
+		variant: variant,
+		ip:      t.IP,
+		e:       t.E,
+		p:       t.P,
+		pInv:    myDes.InversePermutation(t.P),
+		sBox:    variant.SBoxLookup(),
+		bounds:  []float64{1, 1},
+	}
+
+	for box := range a.sBox {
+		a.lat[box] = a.newLAT(box)
+	}
+	a.collectCandidates()
+	return a
+}
+
+// parity возвращает сумму по модулю 2 бит x
+func parity(x uint64) uint8 {
+	return uint8(bits.OnesCount64(x) & 1)
+}
+
+// inputMask переводит маску a входа S-блока box в маску 32-битной половины до расширения E
+func (a *Analyzer) inputMask(box int, in uint8) uint32 {
+	var mask uint32
+	for j := 0; j < 6; j++ {
+		if in&(0x20>>j) != 0 {
+			mask ^= 1 << (32 - a.e[6*box+j])
+		}
+	}
+	return mask
+}
+
+// outputMask переводит маску b выхода S-блока box в маску выхода функции F после перестановки P
+func (a *Analyzer) outputMask(box int, out uint8) uint32 {
+	return uint32(myDes.Permute(uint64(out)<<(28-4*box), a.p, 32))
+}
+
+// sBoxMasks раскладывает маску выхода функции F на маски выходов S-блоков по P^-1
+func (a *Analyzer) sBoxMasks(f uint32) [8]uint8 {
+	var masks [8]uint8
+	s := myDes.Permute(uint64(f), a.pInv, 32)
+	for box := range masks {
+		masks[box] = uint8(s>>(28-4*box)) & 0xf
+	}
+	return masks
+}
+
+// textMask переводит маску состояния L || R после начальной перестановки в маску 64-битного блока
+// открытого текста или шифротекста, чтобы считать четность без перестановки каждого текста
+func (a *Analyzer) textMask(state uint64) uint64 {
+	var mask uint64
+	for j, bit := range a.ip {
+		if state>>(63-j)&1 != 0 {
+			mask |= 1 << (64 - bit)
+		}
+	}
+	return mask
+}
@@ -0,0 +1,204 @@
+package linear
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var (
+	// ErrApproximationRounds возвращается при поиске аппроксимации на неподдерживаемое число раундов
+	ErrApproximationRounds = errors.New("linear: аппроксимации ищутся на 1-16 раундов")
+	// ErrNoApproximation возвращается, если ни одна аппроксимация не удовлетворяет условиям поиска
+	ErrNoApproximation = errors.New("linear: подходящая аппроксимация не найдена")
+)
+
+// maxSearchRounds - наибольшее число раундов, на которое ищутся аппроксимации
+const maxSearchRounds = 16
+
+// Round - линейная аппроксимация функции F одного раунда Input·X XOR Output·F(X, K) = Key·K.
+// Для неактивного раунда Box равен -1, все маски нулевые, а корреляция равна 1
+type Round struct {
+	Box         int     // Активный S-блок, начиная с нуля
+	In          uint8   // Маска входа S-блока (6 бит)
+	Out         uint8   // Маска выхода S-блока (4 бита)
+	Input       uint32  // Маска входа функции F до расширения
+	Output      uint32  // Маска выхода функции F после перестановки P
+	Key         uint64  // Маска 48-битного подключа
+	Correlation float64 // 2 * (вероятность - 1/2), то есть LAT/32
+}
+
+// inactiveRound - раунд, не входящий в аппроксимацию
+var inactiveRound = Round{Box: -1, Correlation: 1}
+
+// Approximation - линейная аппроксимация Rounds раундов DES: четность бит состояния L0 || R0 после
+// начальной перестановки по маскам PlainL, PlainR, XOR четность бит Ln || Rn перед конечной
+// перестановкой по маскам CipherL, CipherR равна четности бит подключей по маскам Trail[i].Key
+// с вероятностью 1/2 + Bias
+type Approximation struct {
+	Rounds           int
+	PlainL, PlainR   uint32
+	CipherL, CipherR uint32
+	Trail            []Round
+	Bias             float64
+}
+
+// ActiveBoxes возвращает число активных S-блоков аппроксимации
+func (ap *Approximation) ActiveBoxes() int {
+	count := 0
+	for _, r := range ap.Trail {
+		if r.Box >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// KeyParity вычисляет правую часть аппроксимации - четность бит подключей по маскам аппроксимации;
+// subKeys[i] - подключ раунда i+1
+func (ap *Approximation) KeyParity(subKeys []uint64) uint8 {
+	var result uint8
+	for i, r := range ap.Trail {
+		result ^= parity(subKeys[i] & r.Key)
+	}
+	return result
+}
+
+// collectCandidates перечисляет аппроксимации функции F с одним активным S-блоком
+func (a *Analyzer) collectCandidates() {
+	a.candidates = []Round{inactiveRound}
+	a.byOutput = make(map[uint32][]Round)
+	for box := 0; box < 8; box++ {
+		for in := 1; in < 64; in++ {
+			for out := 1; out < 16; out++ {
+				value := a.lat[box][in][out]
+				if value == 0 {
+					continue
+				}
+				r := Round{
+					Box:         box,
+					In:          uint8(in),
+					Out:         uint8(out),
+					Input:       a.inputMask(box, uint8(in)),
+					Output:      a.outputMask(box, uint8(out)),
+					Key:         uint64(in) << (42 - 6*box),
+					Correlation: float64(value) / 32,
+				}
+				a.candidates = append(a.candidates, r)
+				a.byOutput[r.Output] = append(a.byOutput[r.Output], r)
+			}
+		}
+	}
+	byCorrelation := func(list []Round) func(i, j int) bool {
+		return func(i, j int) bool {
+			return math.Abs(list[i].Correlation) > math.Abs(list[j].Correlation)
+		}
+	}
+	sort.SliceStable(a.candidates, byCorrelation(a.candidates))
+	for _, list := range a.byOutput {
+		sort.SliceStable(list, byCorrelation(list))
+	}
+}
+
+// BestApproximation находит линейную аппроксимацию rounds раундов с наибольшим смещением.
+//
+// Поиск - метод ветвей и границ Мацуи: аппроксимации первых двух раундов перебираются свободно,
+// а для каждого следующего раунда маска выхода F определяется предыдущими (Output[i] = Input[i-1]
+// XOR Output[i-2]), так что перебирается только маска входа. Ветвь отсекается, если даже при лучших
+// аппроксимациях оставшихся раундов (найденных ранее для меньшего числа раундов) она не превзойдет
+// найденную. Рассматриваются аппроксимации, в каждом раунде которых активно не более одного S-блока;
+// из таких состоят лучшие аппроксимации стандартного DES
+func (a *Analyzer) BestApproximation(rounds int) (*Approximation, error) {
+	return a.search(rounds, nil)
+}
+
+// searcher - состояние поиска аппроксимации в глубину
+type searcher struct {
+	a      *Analyzer
+	rounds int
+	accept func(*Approximation) bool // Дополнительное условие на найденную аппроксимацию
+	trail  []Round
+	best   float64 // Модуль корреляции лучшей найденной аппроксимации
+	result *Approximation
+}
+
+// search находит лучшую аппроксимацию на rounds раундов, для которой accept (если задан) истинно
+func (a *Analyzer) search(rounds int, accept func(*Approximation) bool) (*Approximation, error) {
+	if rounds < 1 || rounds > maxSearchRounds {
+		return nil, ErrApproximationRounds
+	}
+	// Границы для меньшего числа раундов вычисляются по порядку, каждая опирается на предыдущие
+	for r := len(a.bounds); r < rounds; r++ {
+		best, err := a.search(r, nil)
+		if err != nil {
+			return nil, err
+		}
+		a.bounds = append(a.bounds, 2*math.Abs(best.Bias))
+	}
+
+	s := &searcher{a: a, rounds: rounds, accept: accept}
+	s.extend(0, 1)
+	if s.result == nil {
+		return nil, ErrNoApproximation
+	}
+	return s.result, nil
+}
+
+// extend подбирает аппроксимацию раунда i при корреляции corr первых i раундов
+func (s *searcher) extend(i int, corr float64) {
+	if i == s.rounds {
+		if math.Abs(corr) <= s.best {
+			return
+		}
+		ap := s.approximation(corr)
+		if ap.ActiveBoxes() > 0 && (s.accept == nil || s.accept(ap)) {
+			s.best, s.result = math.Abs(corr), ap
+		}
+		return
+	}
+
+	candidates := s.a.candidates
+	if i >= 2 {
+		output := s.trail[i-1].Input ^ s.trail[i-2].Output
+		candidates = s.a.byOutput[output]
+		if output == 0 {
+			candidates = []Round{inactiveRound}
+		}
+	}
+	// На последний раунд границы нет: он может оказаться неактивным
+	remaining := s.a.bounds[s.rounds-i-1]
+	for _, r := range candidates {
+		c := corr * r.Correlation
+		if math.Abs(c)*remaining <= s.best {
+			break
+		}
+		// Два неактивных раунда подряд обнуляют всю аппроксимацию
+		if i == 1 && r.Box < 0 && s.trail[0].Box < 0 {
+			continue
+		}
+		s.trail = append(s.trail[:i], r)
+		s.extend(i+1, c)
+	}
+}
+
+// approximation собирает маски открытого текста и шифротекста по аппроксимациям раундов.
+// Состояния X0 = L0, X1 = R0, X(i+1) = X(i-1) XOR F(Xi) входят в сумму соотношений раундов
+// с масками Output[i+1] XOR Input[i] XOR Output[i-1], промежуточные взаимно уничтожаются
+func (s *searcher) approximation(corr float64) *Approximation {
+	n := s.rounds
+	trail := append([]Round(nil), s.trail[:n]...)
+	ap := &Approximation{
+		Rounds:  n,
+		PlainL:  trail[0].Output,
+		PlainR:  trail[0].Input,
+		CipherR: trail[n-1].Output,
+		Trail:   trail,
+		Bias:    corr / 2,
+	}
+	// При одном раунде X1 = R0 = L1, и его маска уже учтена в PlainR
+	if n > 1 {
+		ap.PlainR ^= trail[1].Output
+		ap.CipherL = trail[n-1].Input ^ trail[n-2].Output
+	}
+	return ap
+}
@@ -0,0 +1,270 @@
+package linear
+
+import (
+	"IB3/myDes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	// ErrExperimentRounds возвращается при эксперименте с неподдерживаемым числом раундов
+	ErrExperimentRounds = errors.New("linear: эксперимент поддерживает 3-16 раундов")
+	// ErrGuessBoxes возвращается, если аппроксимация для алгоритма 2 не затрагивает 1-2 S-блока последнего раунда
+	ErrGuessBoxes = errors.New("linear: аппроксимация для алгоритма 2 должна затрагивать 1-2 S-блока последнего раунда")
+)
+
+// maxGuessBoxes - наибольшее число S-блоков последнего раунда, подключ которых перебирает алгоритм 2
+const maxGuessBoxes = 2
+
+// Sample - известная пара открытый текст - шифротекст
+type Sample struct {
+	Plaintext  uint64
+	Ciphertext uint64
+}
+
+// GenerateSamples шифрует n случайных открытых текстов вариантом DES (nil - стандарт)
+// с rounds раундами на ключе key
+func GenerateSamples(variant *myDes.Variant, key []byte, rounds, n int, rnd *rand.Rand) ([]Sample, error) {
+	b, err := variant.NewCipher(key, rounds)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, n)
+	var buf [myDes.BlockSize]byte
+	for i := range samples {
+		samples[i].Plaintext = rnd.Uint64()
+		binary.BigEndian.PutUint64(buf[:], samples[i].Plaintext)
+		b.Encrypt(buf[:], buf[:])
+		samples[i].Ciphertext = binary.BigEndian.Uint64(buf[:])
+	}
+	return samples, nil
+}
+
+// keyBit определяет правую часть аппроксимации со смещением bias по числу count текстов из n,
+// для которых левая часть равна нулю
+func keyBit(count, n int, bias float64) uint8 {
+	if (2*count > n) == (bias > 0) {
+		return 0
+	}
+	return 1
+}
+
+// Algorithm1Result - результат алгоритма 1 Мацуи
+type Algorithm1Result struct {
+	Approximation *Approximation
+	Samples       int   // Число известных открытых текстов
+	Count         int   // Число текстов, для которых левая часть аппроксимации равна нулю
+	KeyBit        uint8 // Найденная четность бит ключа
+}
+
+// Algorithm1 - алгоритм 1 Мацуи: аппроксимация на все раунды шифра дает один бит - четность бит
+// подключей. Если левая часть чаще равна нулю, правая равна нулю при положительном смещении
+// и единице при отрицательном
+func (a *Analyzer) Algorithm1(ap *Approximation, samples []Sample) *Algorithm1Result {
+	plainMask := a.textMask(uint64(ap.PlainL)<<32 | uint64(ap.PlainR))
+	// После конечной перестановки шифротекст - это Rn || Ln
+	cipherMask := a.textMask(uint64(ap.CipherR)<<32 | uint64(ap.CipherL))
+
+	result := &Algorithm1Result{Approximation: ap, Samples: len(samples)}
+	for _, s := range samples {
+		if parity(s.Plaintext&plainMask)^parity(s.Ciphertext&cipherMask) == 0 {
+			result.Count++
+		}
+	}
+	result.KeyBit = keyBit(result.Count, len(samples), ap.Bias)
+	return result
+}
+
+// Candidate - вариант бит подключа последнего раунда и число текстов, для которых левая часть
+// аппроксимации с этим вариантом равна нулю
+type Candidate struct {
+	SubKey uint64
+	Count  int
+}
+
+// Algorithm2Result - результат алгоритма 2 Мацуи
+type Algorithm2Result struct {
+	Approximation *Approximation
+	Samples       int
+	Boxes         []int       // S-блоки последнего раунда, биты подключа которых перебирались
+	SubKey        uint64      // Лучший вариант бит подключа последнего раунда
+	SubKeyMask    uint64      // Маска перебираемых бит (по 6 бит на S-блок)
+	KeyBit        uint8       // Четность бит подключей первых раундов для лучшего варианта
+	Candidates    []Candidate // Все варианты по убыванию отклонения от половины текстов
+}
+
+// Algorithm2 - алгоритм 2 Мацуи: аппроксимация на все раунды, кроме последнего, дополняется
+// вычислением функции F последнего раунда для каждого варианта бит подключа затронутых S-блоков.
+// Правильный вариант дает наибольшее отклонение числа нулей левой части от половины текстов.
+//
+// Тексты не перебираются для каждого варианта: сначала они раскладываются по счетчикам, индекс
+// которых - входы затронутых S-блоков из шифротекста и четность известной части левой стороны
+func (a *Analyzer) Algorithm2(ap *Approximation, samples []Sample) (*Algorithm2Result, error) {
+	// Маска CipherL относится к X(n) = R(n+1) XOR F(L(n+1), K(n+1)), где n = ap.Rounds
+	masks := a.sBoxMasks(ap.CipherL)
+	result := &Algorithm2Result{Approximation: ap, Samples: len(samples)}
+	for box, mask := range masks {
+		if mask != 0 {
+			result.Boxes = append(result.Boxes, box)
+			result.SubKeyMask |= uint64(0x3f) << (42 - 6*box)
+		}
+	}
+	if len(result.Boxes) == 0 || len(result.Boxes) > maxGuessBoxes {
+		return nil, ErrGuessBoxes
+	}
+
+	plainMask := a.textMask(uint64(ap.PlainL)<<32 | uint64(ap.PlainR))
+	knownMask := a.textMask(uint64(ap.CipherL)<<32 | uint64(ap.CipherR))
+	// Позиции бит шифротекста, которые после расширения попадают на входы перебираемых S-блоков:
+	// бит j состояния после начальной перестановки - это бит ip[j] шифротекста
+	var positions []int
+	for _, box := range result.Boxes {
+		for j := 0; j < 6; j++ {
+			positions = append(positions, a.ip[32+a.e[6*box+j]-1])
+		}
+	}
+
+	counters := make([]int, 1<<(len(positions)+1))
+	for _, s := range samples {
+		index := 0
+		for _, pos := range positions {
+			index = index<<1 | int(s.Ciphertext>>(64-pos)&1)
+		}
+		known := parity(s.Plaintext&plainMask) ^ parity(s.Ciphertext&knownMask)
+		counters[index<<1|int(known)]++
+	}
+
+	guessBits := 6 * len(result.Boxes)
+	for guess := 0; guess < 1<<guessBits; guess++ {
+		c := Candidate{}
+		for i, box := range result.Boxes {
+			c.SubKey |= uint64(guess>>(guessBits-6*(i+1))&0x3f) << (42 - 6*box)
+		}
+		for index, count := range counters {
+			if count == 0 {
+				continue
+			}
+			// Четность выходов перебираемых S-блоков по маскам; для всех блоков один и тот же ключ
+			f := uint8(index & 1)
+			input := index >> 1
+			for i, box := range result.Boxes {
+				shift := guessBits - 6*(i+1)
+				x := (input ^ guess) >> shift & 0x3f
+				f ^= parity(uint64(a.sBox[box][x] & masks[box]))
+			}
+			if f == 0 {
+				c.Count += count
+			}
+		}
+		result.Candidates = append(result.Candidates, c)
+	}
+
+	n := len(samples)
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		return abs(2*result.Candidates[i].Count-n) > abs(2*result.Candidates[j].Count-n)
+	})
+	best := result.Candidates[0]
+	result.SubKey = best.SubKey
+	result.KeyBit = keyBit(best.Count, n, ap.Bias)
+	return result, nil
+}
+
+// GuessApproximation находит аппроксимацию на rounds раундов для алгоритма 2 против rounds+1 раунда:
+// с наибольшим смещением среди затрагивающих 1-2 S-блока следующего раунда, а при равном смещении -
+// с меньшим числом S-блоков, чтобы перебирать меньше бит подключа
+func (a *Analyzer) GuessApproximation(rounds int) (*Approximation, error) {
+	var best *Approximation
+	for limit := 1; limit <= maxGuessBoxes; limit++ {
+		ap, err := a.search(rounds, func(ap *Approximation) bool {
+			boxes := 0
+			for _, mask := range a.sBoxMasks(ap.CipherL) {
+				if mask != 0 {
+					boxes++
+				}
+			}
+			return boxes > 0 && boxes <= limit
+		})
+		if err == ErrNoApproximation {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if best == nil || math.Abs(ap.Bias) > math.Abs(best.Bias) {
+			best = ap
+		}
+	}
+	if best == nil {
+		return nil, ErrNoApproximation
+	}
+	return best, nil
+}
+
+// Experiment - воспроизводимый эксперимент: алгоритмы 1 и 2 против DES с Rounds раундами на
+// случайных известных открытых текстах и сравнение результатов с настоящим ключом
+type Experiment struct {
+	Rounds  int
+	Samples int
+	Key     []byte
+
+	Algorithm1       *Algorithm1Result
+	Algorithm1Actual uint8 // Настоящая четность бит подключей аппроксимации алгоритма 1
+
+	Algorithm2       *Algorithm2Result
+	Algorithm2Actual uint8  // Настоящая четность бит подключей аппроксимации алгоритма 2
+	SubKey           uint64 // Настоящие биты подключа последнего раунда по маске алгоритма 2
+	Rank             int    // Место настоящих бит среди вариантов алгоритма 2, начиная с 1
+}
+
+// Run проводит эксперимент с rounds раундами и samples известными открытыми текстами на ключе key.
+// Для алгоритма 1 берется лучшая аппроксимация на rounds раундов, для алгоритма 2 - аппроксимация
+// на rounds-1 раундов из GuessApproximation
+func (a *Analyzer) Run(key []byte, rounds, samples int, rnd *rand.Rand) (*Experiment, error) {
+	if rounds < 3 || rounds > maxSearchRounds {
+		return nil, ErrExperimentRounds
+	}
+	schedule, err := a.variant.TraceKeySchedule(key)
+	if err != nil {
+		return nil, err
+	}
+	subKeys := make([]uint64, rounds)
+	for i := range subKeys {
+		subKeys[i] = schedule.Rounds[i%16].SubKey
+	}
+
+	first, err := a.BestApproximation(rounds)
+	if err != nil {
+		return nil, err
+	}
+	second, err := a.GuessApproximation(rounds - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := GenerateSamples(a.variant, key, rounds, samples, rnd)
+	if err != nil {
+		return nil, err
+	}
+	e := &Experiment{
+		Rounds:           rounds,
+		Samples:          samples,
+		Key:              key,
+		Algorithm1:       a.Algorithm1(first, data),
+		Algorithm1Actual: first.KeyParity(subKeys),
+		Algorithm2Actual: second.KeyParity(subKeys),
+	}
+	if e.Algorithm2, err = a.Algorithm2(second, data); err != nil {
+		return nil, err
+	}
+	e.SubKey = subKeys[rounds-1] & e.Algorithm2.SubKeyMask
+	for i, c := range e.Algorithm2.Candidates {
+		if c.SubKey == e.SubKey {
+			e.Rank = i + 1
+			break
+		}
+	}
+	return e, nil
+}
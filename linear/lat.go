@@ -0,0 +1,51 @@
+package linear
+
+// LAT - таблица линейных аппроксимаций S-блока: LAT[a][b] - число 6-битных входов x, для которых
+// a·x = b·S(x), минус 32. Соотношение выполняется с вероятностью 1/2 + LAT[a][b]/64
+type LAT [64][16]int
+
+// newLAT строит таблицу линейных аппроксимаций S-блока box
+func (a *Analyzer) newLAT(box int) LAT {
+	var t LAT
+	for in := 0; in < 64; in++ {
+		for out := 0; out < 16; out++ {
+			for x := 0; x < 64; x++ {
+				if parity(uint64(x&in)) == parity(uint64(a.sBox[box][x]&uint8(out))) {
+					t[in][out]++
+				}
+			}
+			t[in][out] -= 32
+		}
+	}
+	return t
+}
+
+// LAT возвращает таблицу линейных аппроксимаций S-блока box (с нуля)
+func (a *Analyzer) LAT(box int) LAT {
+	return a.lat[box]
+}
+
+// Bias возвращает отклонение вероятности соотношения с масками in и out от 1/2
+func (t *LAT) Bias(in, out uint8) float64 {
+	return float64(t[in][out]) / 64
+}
+
+// Max возвращает наибольшее по модулю значение таблицы при ненулевых масках и соответствующие маски
+func (t *LAT) Max() (value int, in, out uint8) {
+	for a := 1; a < 64; a++ {
+		for b := 1; b < 16; b++ {
+			if abs(t[a][b]) > abs(value) {
+				value, in, out = t[a][b], uint8(a), uint8(b)
+			}
+		}
+	}
+	return value, in, out
+}
+
+// abs возвращает модуль целого числа
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
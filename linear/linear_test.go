@@ -0,0 +1,124 @@
+package linear
+
+import (
+	"IB3/myDes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestLAT проверяет свойства таблиц линейных аппроксимаций S-блоков DES
+func TestLAT(t *testing.T) {
+	a := NewAnalyzer(nil)
+	for box := 0; box < 8; box++ {
+		lat := a.LAT(box)
+		if lat[0][0] != 32 {
+			t.Errorf("S%d: LAT[0][0] = %d", box+1, lat[0][0])
+		}
+		for in := 0; in < 64; in++ {
+			for out := 0; out < 16; out++ {
+				if lat[in][out]%2 != 0 {
+					t.Errorf("S%d: LAT[%02x][%x] = %d нечетно", box+1, in, out, lat[in][out])
+				}
+				// Ненулевая маска только с одной стороны дает сбалансированное соотношение
+				if (in == 0) != (out == 0) && lat[in][out] != 0 {
+					t.Errorf("S%d: LAT[%02x][%x] = %d", box+1, in, out, lat[in][out])
+				}
+			}
+		}
+	}
+
+	// Лучшее соотношение из работы Мацуи: S5 с масками 10 и F выполняется в 12 случаях из 64
+	lat := a.LAT(4)
+	if value, in, out := lat.Max(); value != -20 || in != 0x10 || out != 0xf {
+		t.Errorf("S5: максимум %d для %02x -> %x", value, in, out)
+	}
+	if bias := lat.Bias(0x10, 0xf); bias != -0.3125 {
+		t.Errorf("смещение %v", bias)
+	}
+}
+
+// TestBestApproximation сравнивает смещения найденных аппроксимаций с таблицей Мацуи
+// и проверяет аппроксимацию на 3 раунда на случайных текстах
+func TestBestApproximation(t *testing.T) {
+	a := NewAnalyzer(nil)
+	want := map[int]float64{
+		3: 1.5625 / 8,     // 1.56 * 2^-3
+		4: -1.953125 / 32, // -1.95 * 2^-5
+		5: 1.220703125 / 64,
+		6: -1.953125 / 512,
+		7: 1.953125 / 1024,
+		8: -1.220703125 / 2048,
+	}
+	for rounds := 3; rounds <= 8; rounds++ {
+		ap, err := a.BestApproximation(rounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(ap.Bias-want[rounds]) > 1e-12 {
+			t.Errorf("%d раундов: смещение %v, ожидалось %v", rounds, ap.Bias, want[rounds])
+		}
+	}
+	if _, err := a.BestApproximation(17); !errors.Is(err, ErrApproximationRounds) {
+		t.Errorf("17 раундов: %v", err)
+	}
+
+	// Доля текстов, для которых выполняется аппроксимация, должна быть близка к 1/2 + смещение
+	ap, err := a.BestApproximation(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := myDes.FixParity([]byte("LinKey!!"))
+	samples, err := GenerateSamples(nil, key, 3, 4096, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := myDes.TraceKeySchedule(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subKeys := []uint64{schedule.Rounds[0].SubKey, schedule.Rounds[1].SubKey, schedule.Rounds[2].SubKey}
+	result := a.Algorithm1(ap, samples)
+	holds := result.Count
+	if ap.KeyParity(subKeys) == 1 {
+		holds = len(samples) - holds
+	}
+	if measured := float64(holds)/float64(len(samples)) - 0.5; math.Abs(measured-ap.Bias) > 0.03 {
+		t.Errorf("измеренное смещение %v, ожидалось %v", measured, ap.Bias)
+	}
+}
+
+// TestRun проводит эксперимент на 4 и 8 раундах: оба алгоритма должны найти настоящие биты ключа
+func TestRun(t *testing.T) {
+	experiments := []struct {
+		rounds, samples int
+	}{
+		{4, 1 << 10},
+		{8, 1 << 21},
+	}
+	a := NewAnalyzer(nil)
+	key := myDes.FixParity([]byte("LinKey!!"))
+	for _, tc := range experiments {
+		e, err := a.Run(key, tc.rounds, tc.samples, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Algorithm1.KeyBit != e.Algorithm1Actual {
+			t.Errorf("%d раундов: алгоритм 1 нашел бит %d", tc.rounds, e.Algorithm1.KeyBit)
+		}
+		if e.Rank != 1 || e.Algorithm2.SubKey != e.SubKey {
+			t.Errorf("%d раундов: настоящий подключ %012X на месте %d", tc.rounds, e.SubKey, e.Rank)
+		}
+		if e.Algorithm2.KeyBit != e.Algorithm2Actual {
+			t.Errorf("%d раундов: алгоритм 2 нашел бит %d", tc.rounds, e.Algorithm2.KeyBit)
+		}
+		if len(e.Algorithm2.Candidates) != 1<<(6*len(e.Algorithm2.Boxes)) {
+			t.Errorf("%d раундов: %d вариантов", tc.rounds, len(e.Algorithm2.Candidates))
+		}
+	}
+
+	if _, err := a.Run(key, 2, 16, rand.New(rand.NewSource(1))); !errors.Is(err, ErrExperimentRounds) {
+		t.Errorf("2 раунда: %v", err)
+	}
+}
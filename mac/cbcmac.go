@@ -0,0 +1,39 @@
+package mac
+
+import (
+	"IB3/myDes"
+	"crypto/cipher"
+	"hash"
+)
+
+// NewCBCMAC создает CBC-MAC (ISO/IEC 9797-1, алгоритм 1) на основе блочного шифра b.
+// Код - последний блок шифротекста CBC с нулевым вектором инициализации.
+// Без дополнительной защиты CBC-MAC стоек только для сообщений одной фиксированной длины
+func NewCBCMAC(b cipher.Block, padding Padding) (hash.Hash, error) {
+	if padding != PaddingMethod1 && padding != PaddingMethod2 {
+		return nil, ErrPadding
+	}
+	return newCBCMAC(b, b, padding), nil
+}
+
+// NewRetailMAC создает Retail MAC (ISO/IEC 9797-1, алгоритм 3, ANSI X9.19) с 16-байтовым ключом K || K'.
+// Все блоки, кроме последнего, обрабатываются одинарным DES с ключом K, а последний - Triple DES
+// с ключами K, K', K, что совпадает с дополнительным преобразованием e_K(d_K'(H)) из стандарта
+func NewRetailMAC(key []byte, padding Padding) (hash.Hash, error) {
+	if padding != PaddingMethod1 && padding != PaddingMethod2 {
+		return nil, ErrPadding
+	}
+	if len(key) != 2*myDes.BlockSize {
+		return nil, myDes.KeySizeError(len(key))
+	}
+
+	block, err := myDes.NewCipher(key[:myDes.BlockSize])
+	if err != nil {
+		return nil, err
+	}
+	final, err := myDes.NewTripleDES(key)
+	if err != nil {
+		return nil, err
+	}
+	return newCBCMAC(block, final, padding), nil
+}
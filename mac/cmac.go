@@ -0,0 +1,47 @@
+package mac
+
+import (
+	"crypto/cipher"
+	"hash"
+)
+
+// NewCMAC создает CMAC (NIST SP 800-38B) на основе блочного шифра b с блоком 8 байт (DES, Triple DES)
+// или 16 байт. В отличие от CBC-MAC, CMAC стоек для сообщений произвольной длины
+func NewCMAC(b cipher.Block) (hash.Hash, error) {
+	// Константа R_b определяется размером блока
+	var rb byte
+	switch b.BlockSize() {
+	case 8:
+		rb = 0x1b
+	case 16:
+		rb = 0x87
+	default:
+		return nil, ErrBlockSize
+	}
+
+	// Подключи: L = E_K(0), K1 = L·x, K2 = K1·x в поле GF(2^n)
+	l := make([]byte, b.BlockSize())
+	b.Encrypt(l, l)
+	k1 := double(l, rb)
+	k2 := double(k1, rb)
+
+	m := newCBCMAC(b, b, 0)
+	m.k1, m.k2 = k1, k2
+	return m, nil
+}
+
+// double умножает элемент поля GF(2^n) на x: сдвигает блок на один бит влево
+// и при переносе старшего бита выполняет операцию XOR с константой rb
+func double(block []byte, rb byte) []byte {
+	result := make([]byte, len(block))
+	for i := range block {
+		result[i] = block[i] << 1
+		if i+1 < len(block) {
+			result[i] |= block[i+1] >> 7
+		}
+	}
+	if block[0]&0x80 != 0 {
+		result[len(result)-1] ^= rb
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+// Package mac реализует коды аутентичности сообщений на основе блочного шифра:
+// CBC-MAC, Retail MAC (ISO/IEC 9797-1, алгоритм 3) и CMAC (NIST SP 800-38B).
+// Все коды реализуют интерфейс hash.Hash и работают с любым cipher.Block, в том числе с шифрами пакета myDes
+package mac
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"hash"
+)
+
+// MinTagSize - минимальная длина усеченного кода в байтах, которую принимает Equal
+const MinTagSize = 4
+
+// ErrPadding возвращается для неизвестного метода дополнения
+var ErrPadding = errors.New("mac: неизвестный метод дополнения")
+
+// ErrBlockSize возвращается, если размер блока шифра не поддерживается CMAC
+var ErrBlockSize = errors.New("mac: CMAC поддерживает только блоки по 8 и 16 байт")
+
+// Padding определяет метод дополнения последнего блока по ISO/IEC 9797-1
+type Padding int
+
+const (
+	PaddingMethod1 Padding = iota + 1 // Нулевые байты до конца блока; пустое сообщение дополняется целым блоком
+	PaddingMethod2                    // Байт 0x80 и нулевые байты; к выровненному сообщению добавляется целый блок
+)
+
+// String возвращает название метода дополнения
+func (p Padding) String() string {
+	switch p {
+	case PaddingMethod1:
+		return "ISO 9797-1 method 1"
+	case PaddingMethod2:
+		return "ISO 9797-1 method 2"
+	default:
+		return "unknown"
+	}
+}
+
+// Equal сравнивает вычисленный код с проверяемым за постоянное время.
+// Проверяемый код может быть усечен до первых байт вычисленного, но не короче MinTagSize
+func Equal(mac, tag []byte) bool {
+	if len(tag) < MinTagSize || len(tag) > len(mac) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(mac[:len(tag)], tag) == 1
+}
+
+// cbcMAC - общая часть всех кодов: цепочка CBC с нулевым начальным значением.
+// Последний блок сообщения не шифруется, пока не станет известно, что за ним нет данных,
+// потому что его обработка зависит от метода дополнения
+type cbcMAC struct {
+	block   cipher.Block // Шифр для всех блоков, кроме последнего
+	final   cipher.Block // Шифр для последнего блока
+	padding Padding      // Метод дополнения (не используется в CMAC)
+	k1, k2  []byte       // Подключи CMAC; если заданы, последний блок обрабатывается по SP 800-38B
+
+	x   []byte // Текущее значение цепочки
+	buf []byte // Последний необработанный блок
+	n   int    // Количество байт в buf
+}
+
+// newCBCMAC создает цепочку CBC-MAC для шифров block и final
+func newCBCMAC(block, final cipher.Block, padding Padding) *cbcMAC {
+	size := block.BlockSize()
+	return &cbcMAC{
+		block:   block,
+		final:   final,
+		padding: padding,
+		x:       make([]byte, size),
+		buf:     make([]byte, size),
+	}
+}
+
+var _ hash.Hash = (*cbcMAC)(nil)
+
+// Size возвращает длину кода в байтах
+func (m *cbcMAC) Size() int {
+	return len(m.x)
+}
+
+// BlockSize возвращает размер блока шифра
+func (m *cbcMAC) BlockSize() int {
+	return len(m.x)
+}
+
+// Reset сбрасывает состояние для вычисления нового кода тем же ключом
+func (m *cbcMAC) Reset() {
+	clear(m.x)
+	m.n = 0
+}
+
+// Write добавляет данные к сообщению. Ошибку никогда не возвращает
+func (m *cbcMAC) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		// Заполненный блок шифруется только тогда, когда за ним пришли новые данные
+		if m.n == len(m.buf) {
+			m.chain(m.block, m.buf)
+			m.n = 0
+		}
+		k := copy(m.buf[m.n:], p)
+		m.n += k
+		p = p[k:]
+	}
+	return written, nil
+}
+
+// chain выполняет операцию XOR блока с текущим значением цепочки и шифрует результат
+func (m *cbcMAC) chain(b cipher.Block, block []byte) {
+	subtle.XORBytes(m.x, m.x, block)
+	b.Encrypt(m.x, m.x)
+}
+
+// Sum дописывает код к in. Состояние не изменяется, поэтому можно продолжить запись
+func (m *cbcMAC) Sum(in []byte) []byte {
+	// Работаем с копией, чтобы Sum можно было вызывать посередине сообщения
+	state := *m
+	state.x = append([]byte(nil), m.x...)
+	last := make([]byte, len(m.buf))
+	copy(last, m.buf[:m.n])
+
+	switch {
+	case m.k1 != nil:
+		// CMAC: полный последний блок маскируется подключом K1, неполный дополняется 10...0 и маскируется K2
+		if m.n == len(last) {
+			subtle.XORBytes(last, last, m.k1)
+		} else {
+			last[m.n] = 0x80
+			subtle.XORBytes(last, last, m.k2)
+		}
+
+	case m.padding == PaddingMethod2:
+		// Выровненное сообщение получает дополнительный блок 0x80 00 ... 00
+		if m.n == len(last) {
+			state.chain(state.block, last)
+			clear(last)
+			last[0] = 0x80
+		} else {
+			last[m.n] = 0x80
+		}
+	}
+
+	state.chain(state.final, last)
+	return append(in, state.x...)
+}
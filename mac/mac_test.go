@@ -0,0 +1,197 @@
+package mac
+
+import (
+	"IB3/myDes"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// message - сообщение из примеров NIST для CMAC
+var message = mustHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" +
+	"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+// TestCMACVectors проверяет CMAC по примерам NIST для TDEA и AES (RFC 4493)
+func TestCMACVectors(t *testing.T) {
+	tdea3, err := myDes.NewTripleDES(mustHex("0123456789abcdef23456789abcdef01456789abcdef0123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tdea2, err := myDes.NewTripleDES(mustHex("0123456789abcdef23456789abcdef01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesBlock, err := aes.NewCipher(mustHex("2b7e151628aed2a6abf7158809cf4f3c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		block cipher.Block
+		len   int
+		tag   string
+	}{
+		{"TDEA3", tdea3, 0, "7db0d37df936c550"},
+		{"TDEA3", tdea3, 16, "30239cf1f52e6609"},
+		{"TDEA3", tdea3, 20, "6c9f3ee4923f6be2"},
+		{"TDEA3", tdea3, 32, "99429bd0bf7904e5"},
+		{"TDEA2", tdea2, 0, "79ce52a7f786a960"},
+		{"TDEA2", tdea2, 16, "cc18a0b79af2413b"},
+		{"TDEA2", tdea2, 32, "9cd33580f9b64dfb"},
+		{"AES-128", aesBlock, 0, "bb1d6929e95937287fa37d129b756746"},
+		{"AES-128", aesBlock, 16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{"AES-128", aesBlock, 40, "dfa66747de9ae63030ca32611497c827"},
+		{"AES-128", aesBlock, 64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+	for _, tt := range tests {
+		h, err := NewCMAC(tt.block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Write(message[:tt.len])
+		if got := hex.EncodeToString(h.Sum(nil)); got != tt.tag {
+			t.Errorf("%s, %d байт: %s, ожидалось %s", tt.name, tt.len, got, tt.tag)
+		}
+	}
+}
+
+// referenceCBCMAC вычисляет CBC-MAC через crypto/des; при retail последний блок дополнительно
+// расшифровывается ключом K' и шифруется ключом K
+func referenceCBCMAC(key []byte, data []byte, padding Padding, retail bool) []byte {
+	k, _ := des.NewCipher(key[:8])
+	padded := append([]byte(nil), data...)
+	if padding == PaddingMethod2 {
+		padded = append(padded, 0x80)
+	}
+	for len(padded)%8 != 0 || len(padded) == 0 {
+		padded = append(padded, 0)
+	}
+
+	x := make([]byte, 8)
+	for i := 0; i < len(padded); i += 8 {
+		for j := range x {
+			x[j] ^= padded[i+j]
+		}
+		k.Encrypt(x, x)
+	}
+	if retail {
+		k2, _ := des.NewCipher(key[8:16])
+		k2.Decrypt(x, x)
+		k.Encrypt(x, x)
+	}
+	return x
+}
+
+// TestCBCMACAndRetailMAC сверяет CBC-MAC и Retail MAC с реализацией на crypto/des
+func TestCBCMACAndRetailMAC(t *testing.T) {
+	key := mustHex("0123456789abcdeffedcba9876543210")
+	block, err := myDes.NewCipher(key[:8])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, padding := range []Padding{PaddingMethod1, PaddingMethod2} {
+		for _, n := range []int{0, 1, 7, 8, 9, 16, 22, 64} {
+			data := message[:n]
+
+			cbc, err := NewCBCMAC(block, padding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cbc.Write(data)
+			if got, want := cbc.Sum(nil), referenceCBCMAC(key, data, padding, false); !bytes.Equal(got, want) {
+				t.Errorf("CBC-MAC, %s, %d байт: %x, ожидалось %x", padding, n, got, want)
+			}
+
+			retail, err := NewRetailMAC(key, padding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			retail.Write(data)
+			if got, want := retail.Sum(nil), referenceCBCMAC(key, data, padding, true); !bytes.Equal(got, want) {
+				t.Errorf("Retail MAC, %s, %d байт: %x, ожидалось %x", padding, n, got, want)
+			}
+		}
+	}
+}
+
+// TestIncrementalWrite проверяет, что код не зависит от разбиения сообщения на части и что Sum не меняет состояние
+func TestIncrementalWrite(t *testing.T) {
+	key := mustHex("0123456789abcdef23456789abcdef01")
+	block, _ := myDes.NewTripleDES(key)
+	constructors := map[string]func() hash.Hash{
+		"CBC-MAC": func() hash.Hash { h, _ := NewCBCMAC(block, PaddingMethod2); return h },
+		"Retail":  func() hash.Hash { h, _ := NewRetailMAC(key, PaddingMethod1); return h },
+		"CMAC":    func() hash.Hash { h, _ := NewCMAC(block); return h },
+	}
+
+	for name, newHash := range constructors {
+		whole := newHash()
+		whole.Write(message)
+		want := whole.Sum(nil)
+
+		for _, chunk := range []int{1, 3, 8, 13} {
+			h := newHash()
+			for i := 0; i < len(message); i += chunk {
+				h.Write(message[i:min(i+chunk, len(message))])
+				h.Sum(nil)
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%s, части по %d байт: %x, ожидалось %x", name, chunk, got, want)
+			}
+
+			h.Reset()
+			h.Write(message)
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("%s после Reset: %x, ожидалось %x", name, got, want)
+			}
+		}
+	}
+}
+
+// TestEqual проверяет сравнение полных и усеченных кодов
+func TestEqual(t *testing.T) {
+	tag := mustHex("99429bd0bf7904e5")
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"99429bd0bf7904e5", true},
+		{"99429bd0", true},
+		{"99429b", false},
+		{"99429bd0bf7904e4", false},
+		{"99429bd0bf7904e500", false},
+	}
+	for _, tt := range tests {
+		if got := Equal(tag, mustHex(tt.tag)); got != tt.want {
+			t.Errorf("Equal(%s) = %v, ожидалось %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+// TestErrors проверяет отказ от неверных параметров
+func TestErrors(t *testing.T) {
+	if _, err := NewRetailMAC([]byte("12345678"), PaddingMethod1); err == nil {
+		t.Error("Retail MAC принял 8-байтовый ключ")
+	}
+	if _, err := NewRetailMAC([]byte("1234567812345678"), PaddingMethod1); err == nil {
+		t.Error("Retail MAC принял ключ с K = K'")
+	}
+	block, _ := myDes.NewCipher([]byte("12345678"))
+	if _, err := NewCBCMAC(block, 0); err != ErrPadding {
+		t.Errorf("NewCBCMAC с неизвестным дополнением: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package myDes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"hash"
+)
+
+// macSize - длина кода аутентичности HMAC-SHA256 в байтах
+const macSize = sha256.Size
+
+// IntegrityError описывает контейнер, не прошедший проверку целостности
+type IntegrityError struct {
+	Reason string // Причина ошибки
+}
+
+func (e *IntegrityError) Error() string {
+	return "myDes: нарушена целостность: " + e.Reason
+}
+
+// Метки, с которыми из основного ключа вырабатываются ключи контейнера с кодом аутентичности
+const (
+	cipherKeyLabel = "myDes container encryption key"  // Ключ шифрования
+	macKeyLabel    = "myDes container HMAC-SHA256 key" // Ключ HMAC
+)
+
+// deriveKey вырабатывает из основного ключа key ключ длиной size байт (не больше 32) для назначения label
+func deriveKey(key, label string, size int) []byte {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(label))
+	return h.Sum(nil)[:size]
+}
+
+// containerKeys возвращает ключ шифрования и ключ HMAC контейнера c для основного ключа key, уже проверенного
+// checkKey. Без кода аутентичности основной ключ используется для шифрования напрямую. С кодом оба ключа
+// вырабатываются из основного с разными метками, и основной ключ не используется ни для шифрования,
+// ни для аутентификации. Проверки основного ключа тогда шифрование не защищают, поэтому выработанный ключ
+// шифрования проверяется так же, как ключ из пароля: вариант ключей Triple DES - всегда, слабые ключи -
+// при WithKeyValidation
+func (d *MyDES) containerKeys(c *Container, key string) (cipherKey string, macKey []byte, err error) {
+	if len(c.MAC) == 0 {
+		return key, nil, nil
+	}
+	cipherKey, err = d.checkKey(string(deriveKey(key, cipherKeyLabel, c.Algorithm.keySize())), true)
+	if err != nil {
+		return "", nil, err
+	}
+	return cipherKey, deriveKey(key, macKeyLabel, macSize), nil
+}
+
+// newContainerMAC создает HMAC-SHA256 с ключом macKey и сразу добавляет в него заголовок контейнера c
+// (включая вектор инициализации и параметры выработки ключа). Шифротекст дописывается по мере обработки
+func newContainerMAC(c *Container, macKey []byte) hash.Hash {
+	h := hmac.New(sha256.New, macKey)
+	h.Write(c.Header())
+	return h
+}
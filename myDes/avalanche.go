@@ -0,0 +1,138 @@
+package myDes
+
+import (
+	"encoding/csv"
+	"io"
+	"math/bits"
+	"strconv"
+)
+
+// AvalancheRow - результат инвертирования одного бита: число бит состояния L || R,
+// отличающихся от исходного шифрования после каждого раунда
+type AvalancheRow struct {
+	Bit     int   // Номер инвертированного бита, начиная с 1 со старшего, как в стандарте DES
+	Changed []int // Число измененных бит после каждого раунда (из 64)
+}
+
+// AvalancheReport - результаты лавинного анализа блока и ключа
+type AvalancheReport struct {
+	Rounds    int            // Число раундов: 16 для DES, 48 для Triple DES (или заданное WithRounds)
+	Plaintext []AvalancheRow // Инвертирование каждого бита открытого текста
+	Key       []AvalancheRow // Инвертирование каждого бита ключа (биты четности не меняют результат)
+}
+
+// roundStates возвращает состояние L || R после каждого раунда шифрования блока
+func (d *MyDES) roundStates(block []byte, key string) ([]uint64, error) {
+	trace, err := d.TraceBlock(block, key, false)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]uint64, len(trace.Rounds))
+	for i, r := range trace.Rounds {
+		states[i] = uint64(r.NextLeft)<<32 | uint64(r.NextRight)
+	}
+	return states, nil
+}
+
+// Avalanche шифрует 8-байтовый блок выбранным алгоритмом, затем по очереди инвертирует каждый бит блока
+// и каждый бит ключа и считает, сколько бит состояния изменилось после каждого раунда.
+// Конечная перестановка не меняет числа измененных бит, поэтому последний раунд соответствует шифротексту
+func (d *MyDES) Avalanche(block []byte, key string) (*AvalancheReport, error) {
+	base, err := d.roundStates(block, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ключи с одним инвертированным битом могут оказаться слабыми, поэтому для них проверка отключается
+	probe := *d
+	probe.keyValidation = false
+
+	report := &AvalancheReport{Rounds: len(base)}
+	changed := func(states []uint64) []int {
+		counts := make([]int, len(states))
+		for i := range states {
+			counts[i] = bits.OnesCount64(states[i] ^ base[i])
+		}
+		return counts
+	}
+
+	flipped := make([]byte, len(block))
+	for bit := 0; bit < 8*len(block); bit++ {
+		copy(flipped, block)
+		flipped[bit/8] ^= 0x80 >> (bit % 8)
+		states, err := probe.roundStates(flipped, key)
+		if err != nil {
+			return nil, err
+		}
+		report.Plaintext = append(report.Plaintext, AvalancheRow{Bit: bit + 1, Changed: changed(states)})
+	}
+
+	flippedKey := []byte(key)
+	for bit := 0; bit < 8*len(key); bit++ {
+		flippedKey[bit/8] ^= 0x80 >> (bit % 8)
+		states, err := probe.roundStates(block, string(flippedKey))
+		flippedKey[bit/8] ^= 0x80 >> (bit % 8)
+		if err != nil {
+			return nil, err
+		}
+		report.Key = append(report.Key, AvalancheRow{Bit: bit + 1, Changed: changed(states)})
+	}
+	return report, nil
+}
+
+// PlaintextMean возвращает среднее по всем битам открытого текста число измененных бит после каждого раунда
+func (r *AvalancheReport) PlaintextMean() []float64 {
+	return meanChanged(r.Plaintext)
+}
+
+// KeyMean возвращает среднее по всем битам ключа число измененных бит после каждого раунда
+func (r *AvalancheReport) KeyMean() []float64 {
+	return meanChanged(r.Key)
+}
+
+// meanChanged возвращает среднее число измененных бит после каждого раунда по всем строкам
+func meanChanged(rows []AvalancheRow) []float64 {
+	if len(rows) == 0 {
+		return nil
+	}
+	mean := make([]float64, len(rows[0].Changed))
+	for _, row := range rows {
+		for i, c := range row.Changed {
+			mean[i] += float64(c)
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(rows))
+	}
+	return mean
+}
+
+// WriteCSV записывает отчет в формате CSV: строка на каждый инвертированный бит
+// со столбцами "input" (plaintext или key), "bit" и числом измененных бит после каждого раунда
+func (r *AvalancheReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"input", "bit"}
+	for round := 1; round <= r.Rounds; round++ {
+		header = append(header, "round"+strconv.Itoa(round))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, part := range []struct {
+		name string
+		rows []AvalancheRow
+	}{{"plaintext", r.Plaintext}, {"key", r.Key}} {
+		for _, row := range part.rows {
+			record := []string{part.name, strconv.Itoa(row.Bit)}
+			for _, c := range row.Changed {
+				record = append(record, strconv.Itoa(c))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
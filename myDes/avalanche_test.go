@@ -0,0 +1,48 @@
+package myDes
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+// TestAvalanche проверяет лавинный эффект DES: после первого раунда инвертирование бита открытого текста
+// меняет немного бит, к последнему раунду - в среднем около половины из 64
+func TestAvalanche(t *testing.T) {
+	report, err := NewMyDES("").Avalanche([]byte("Avalanch"), "Secret_8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rounds != 16 || len(report.Plaintext) != 64 || len(report.Key) != 64 {
+		t.Fatalf("раундов %d, строк %d и %d", report.Rounds, len(report.Plaintext), len(report.Key))
+	}
+
+	plain := report.PlaintextMean()
+	if plain[0] > 8 {
+		t.Errorf("после раунда 1 изменено в среднем %.1f бит", plain[0])
+	}
+	for _, mean := range [][]float64{plain, report.KeyMean()} {
+		if mean[15] < 28 || mean[15] > 36 {
+			t.Errorf("после раунда 16 изменено в среднем %.1f бит", mean[15])
+		}
+	}
+
+	// Биты четности (каждый восьмой бит ключа) отбрасываются PC-1 и ничего не меняют
+	for _, row := range report.Key {
+		if row.Bit%8 == 0 && row.Changed[15] != 0 {
+			t.Errorf("бит четности %d изменил %d бит", row.Bit, row.Changed[15])
+		}
+	}
+
+	var b strings.Builder
+	if err := report.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1+64+64 || len(records[0]) != 2+16 || records[1][0] != "plaintext" || records[65][0] != "key" {
+		t.Errorf("CSV: %d строк, заголовок %v", len(records), records[0])
+	}
+}
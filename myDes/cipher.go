@@ -0,0 +1,76 @@
+package myDes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"strconv"
+)
+
+// BlockSize - размер блока DES в байтах
+const BlockSize = 8
+
+// MaxRounds - наибольшее число раундов, которое можно задать WithRounds и NewReducedCipher
+const MaxRounds = 64
+
+// RoundsError описывает недопустимое число раундов
+type RoundsError int
+
+func (r RoundsError) Error() string {
+	return "myDes: недопустимое число раундов " + strconv.Itoa(int(r)) + ", ожидалось от 1 до " + strconv.Itoa(MaxRounds)
+}
+
+// KeySizeError описывает ошибку неверной длины ключа
+type KeySizeError int
+
+func (k KeySizeError) Error() string {
+	return "myDes: неверный размер ключа " + strconv.Itoa(int(k))
+}
+
+// NewCipher создает блочный шифр DES с 8-байтовым ключом, совместимый с пакетом crypto/cipher.
+// Подключи вычисляются при создании, и шифр можно одновременно использовать из нескольких горутин
+func NewCipher(key []byte) (cipher.Block, error) {
+	if len(key) != BlockSize {
+		return nil, KeySizeError(len(key))
+	}
+	return newDESBlock(string(key), nil, 0), nil
+}
+
+// NewReducedCipher создает блочный шифр DES с 8-байтовым ключом и числом раундов от 1 до MaxRounds.
+// Для числа раундов больше 16 расписание ключей повторяется: раунд i использует подключ i по модулю 16.
+// Ослабленный шифр нужен для упражнений по дифференциальному и линейному криптоанализу
+func NewReducedCipher(key []byte, rounds int) (cipher.Block, error) {
+	return (*Variant)(nil).NewCipher(key, rounds)
+}
+
+// BlockSize возвращает размер блока шифра
+func (d *MyDES) BlockSize() int {
+	return BlockSize
+}
+
+// Encrypt шифрует первый блок из src и записывает результат в dst
+func (d *MyDES) Encrypt(dst, src []byte) {
+	d.cryptBlock(dst, src, false, nil)
+}
+
+// Decrypt расшифровывает первый блок из src и записывает результат в dst
+func (d *MyDES) Decrypt(dst, src []byte) {
+	d.cryptBlock(dst, src, true, nil)
+}
+
+// cryptBlock пропускает один 8-байтовый блок через начальную перестановку, сеть Фейстеля и конечную перестановку.
+// hook, если он задан, вызывается перед каждым раундом
+func (d *MyDES) cryptBlock(dst, src []byte, isDecode bool, hook roundHook) {
+	if len(src) < BlockSize {
+		panic("myDes: входные данные меньше блока")
+	}
+	if len(dst) < BlockSize {
+		panic("myDes: выходной буфер меньше блока")
+	}
+	if d.schedule == nil {
+		panic("myDes: шифр создан без ключа, используйте NewCipher")
+	}
+
+	block := binary.BigEndian.Uint64(src)
+	block = d.endReplaceBlock(d.iteration(d.initReplaceBlock(block), d.schedule, isDecode, hook))
+	binary.BigEndian.PutUint64(dst, block)
+}
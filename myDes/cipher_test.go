@@ -0,0 +1,47 @@
+package myDes
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestNewCipherCBC проверяет, что NewCipher в связке с cipher.NewCBCEncrypter дает тот же шифротекст, что и Encode
+func TestNewCipherCBC(t *testing.T) {
+	key, iv := []byte("12345678"), []byte("01234567")
+	plain := []byte("Шифрование блоками по восемь байт!!")
+	plain = append(plain, make([]byte, (BlockSize-len(plain)%BlockSize)%BlockSize)...)
+
+	block, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+
+	var hexText strings.Builder
+	for i := 0; i < len(encrypted); i += BlockSize {
+		hexText.WriteString(binaryToHex(binary.BigEndian.Uint64(encrypted[i:])))
+	}
+	if want := mustEncode(t, NewMyDES(string(iv), WithPadding(PaddingZero)), string(plain), string(key)); hexText.String() != want {
+		t.Fatalf("CBC через cipher.Block = %s, ожидалось %s", hexText.String(), want)
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+	if !bytes.Equal(decrypted, plain) {
+		t.Fatalf("расшифровано %q, ожидалось %q", decrypted, plain)
+	}
+}
+
+// TestNewCipherKeySize проверяет отказ от ключей неверной длины
+func TestNewCipherKeySize(t *testing.T) {
+	_, err := NewCipher([]byte("short"))
+	var sizeErr KeySizeError
+	if !errors.As(err, &sizeErr) || int(sizeErr) != 5 {
+		t.Fatalf("ожидалась KeySizeError(5), получено %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package myDes
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentUse одновременно шифрует и расшифровывает одними и теми же экземплярами из многих горутин.
+// Гонки данных обнаруживаются при запуске с флагом -race: go test -race ./myDes
+func TestConcurrentUse(t *testing.T) {
+	desBlock, err := NewCipher([]byte("Secret_8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tripleBlock, err := NewTripleDES([]byte("Super_Secret_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := []cipher.Block{desBlock, tripleBlock}
+	d := NewMyDES("01234567", WithMode(ModeCTR), WithWorkers(4), WithMAC())
+
+	// Эталонные результаты вычисляются заранее в одной горутине
+	plain := []byte(randomInput(16, 4096))
+	want := make([][]byte, len(blocks))
+	for i, b := range blocks {
+		want[i] = make([]byte, BlockSize)
+		b.Encrypt(want[i], plain)
+	}
+	wantEncoded := mustEncode(t, d, string(plain), "Secret_8")
+
+	const goroutines = 32
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				for i, b := range blocks {
+					got := make([]byte, BlockSize)
+					b.Encrypt(got, plain)
+					if !bytes.Equal(got, want[i]) {
+						errs <- "Encrypt дал другой результат"
+						return
+					}
+					b.Decrypt(got, got)
+					if !bytes.Equal(got, plain[:BlockSize]) {
+						errs <- "Decrypt дал другой результат"
+						return
+					}
+				}
+			}
+
+			if got, err := d.Encode(string(plain), "Secret_8"); err != nil || got != wantEncoded {
+				errs <- "Encode дал другой результат"
+				return
+			}
+			sealed, err := d.Seal(plain, "Secret_8")
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+			if opened, err := d.Open(sealed, "Secret_8"); err != nil || !bytes.Equal(opened, plain) {
+				errs <- "Open не восстановил открытый текст"
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,253 @@
+package myDes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Формат контейнера (все числа - big-endian):
+//
+//	magic      4 байта  "MDES"
+//	version    1 байт   ContainerVersion
+//	algorithm  1 байт   Algorithm
+//	mode       1 байт   Mode
+//	padding    1 байт   Padding
+//	variant    8 байт   идентификатор таблиц варианта DES (см. Variant.ID)
+//	rounds     1 байт   число раундов сети Фейстеля
+//	ivLen      1 байт   длина вектора инициализации, затем сам вектор
+//	kdf        1 байт   KDF; если не KDFNone, далее saltLen (1 байт), соль и три параметра по 4 байта
+//	macLen     1 байт   длина кода аутентичности в конце контейнера (0 - без кода)
+//	ciphertext          шифротекст до конца контейнера за вычетом macLen байт
+//	mac        macLen байт  HMAC-SHA256 от заголовка и шифротекста (см. WithMAC)
+//
+// Значения Algorithm, Mode, Padding и KDF записываются в контейнер, поэтому их нельзя переупорядочивать.
+
+// ContainerMagic - сигнатура, с которой начинается контейнер
+const ContainerMagic = "MDES"
+
+// ContainerVersion - версия формата контейнера
+const ContainerVersion = 1
+
+// ErrNotContainer возвращается, если данные не начинаются с сигнатуры контейнера
+var ErrNotContainer = errors.New("myDes: данные не являются контейнером myDes")
+
+// MismatchError возвращается, если контейнер зашифрован с другими таблицами DES или другим числом раундов,
+// чем заданы в MyDES: с ними контейнер расшифровался бы в бессмысленные данные
+type MismatchError struct {
+	Reason string // Какой параметр не совпадает
+}
+
+func (e *MismatchError) Error() string {
+	return "myDes: контейнер зашифрован с другими параметрами: " + e.Reason
+}
+
+// ContainerError описывает поврежденный или неподдерживаемый заголовок контейнера
+type ContainerError struct {
+	Reason string // Причина ошибки
+}
+
+func (e *ContainerError) Error() string {
+	return "myDes: некорректный контейнер: " + e.Reason
+}
+
+// Container - зашифрованное сообщение вместе с параметрами, необходимыми для расшифровки
+type Container struct {
+	Algorithm  Algorithm // Блочный шифр
+	Mode       Mode      // Режим работы блочного шифра
+	Padding    Padding   // Схема дополнения
+	Variant    [8]byte   // Идентификатор таблиц варианта DES (см. Variant.ID)
+	Rounds     uint8     // Число раундов сети Фейстеля
+	IV         []byte    // Вектор инициализации
+	KDF        KDFParams // Параметры выработки ключа
+	CipherText []byte    // Шифротекст
+	MAC        []byte    // Код аутентичности (может отсутствовать)
+}
+
+// IsContainer сообщает, начинаются ли данные с сигнатуры контейнера
+func IsContainer(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ContainerMagic))
+}
+
+// Header возвращает заголовок контейнера - все байты, предшествующие шифротексту
+func (c *Container) Header() []byte {
+	header := make([]byte, 0, 32)
+	header = append(header, ContainerMagic...)
+	header = append(header, ContainerVersion, byte(c.Algorithm), byte(c.Mode), byte(c.Padding))
+	header = append(header, c.Variant[:]...)
+	header = append(header, c.Rounds)
+	header = append(header, byte(len(c.IV)))
+	header = append(header, c.IV...)
+
+	header = append(header, byte(c.KDF.KDF))
+	if c.KDF.KDF != KDFNone {
+		header = append(header, byte(len(c.KDF.Salt)))
+		header = append(header, c.KDF.Salt...)
+		for _, param := range c.KDF.Params {
+			header = binary.BigEndian.AppendUint32(header, param)
+		}
+	}
+
+	return append(header, byte(len(c.MAC)))
+}
+
+// MarshalBinary записывает контейнер в двоичном виде
+func (c *Container) MarshalBinary() ([]byte, error) {
+	if len(c.IV) > 255 || len(c.KDF.Salt) > 255 || len(c.MAC) > 255 {
+		return nil, &ContainerError{Reason: "длина вектора инициализации, соли или кода аутентичности больше 255 байт"}
+	}
+
+	data := c.Header()
+	data = append(data, c.CipherText...)
+	return append(data, c.MAC...), nil
+}
+
+// UnmarshalBinary читает контейнер из двоичного вида и проверяет его заголовок
+func (c *Container) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	macLen, err := c.readHeader(r)
+	if err != nil {
+		return err
+	}
+
+	rest := data[len(data)-r.Len():]
+	if len(rest) < macLen {
+		return &ContainerError{Reason: "код аутентичности обрезан"}
+	}
+	c.CipherText = rest[:len(rest)-macLen]
+	c.MAC = rest[len(rest)-macLen:]
+	if macLen == 0 {
+		c.MAC = nil
+	}
+
+	return c.validate()
+}
+
+// readHeader читает из r заголовок контейнера до начала шифротекста и возвращает длину кода аутентичности.
+// Параметры заголовка не проверяются, для этого нужно вызвать validate
+func (c *Container) readHeader(r io.Reader) (int, error) {
+	cr := containerReader{r: r}
+	if magic := cr.readBytes(len(ContainerMagic)); string(magic) != ContainerMagic {
+		if cr.err != nil && !errors.Is(cr.err, io.EOF) && !errors.Is(cr.err, io.ErrUnexpectedEOF) {
+			return 0, cr.err
+		}
+		return 0, ErrNotContainer
+	}
+
+	if version := cr.readByte(); cr.err == nil && version != ContainerVersion {
+		return 0, &ContainerError{Reason: "неподдерживаемая версия " + strconv.Itoa(int(version))}
+	}
+	c.Algorithm, c.Mode, c.Padding = Algorithm(cr.readByte()), Mode(cr.readByte()), Padding(cr.readByte())
+	copy(c.Variant[:], cr.readBytes(len(c.Variant)))
+	c.Rounds = cr.readByte()
+	c.IV = cr.readBytes(int(cr.readByte()))
+
+	c.KDF = KDFParams{KDF: KDF(cr.readByte())}
+	if c.KDF.KDF != KDFNone {
+		c.KDF.Salt = cr.readBytes(int(cr.readByte()))
+		for i := range c.KDF.Params {
+			c.KDF.Params[i] = cr.readUint32()
+		}
+	}
+
+	macLen := int(cr.readByte())
+	switch {
+	case errors.Is(cr.err, io.EOF) || errors.Is(cr.err, io.ErrUnexpectedEOF):
+		return 0, &ContainerError{Reason: "заголовок обрезан"}
+	case cr.err != nil:
+		return 0, cr.err
+	}
+	return macLen, nil
+}
+
+// validate проверяет, что параметры заголовка известны и согласованы между собой
+func (c *Container) validate() error {
+	switch {
+	case c.Algorithm > AlgorithmTripleDES:
+		return &ContainerError{Reason: "неизвестный алгоритм " + strconv.Itoa(int(c.Algorithm))}
+	case c.Mode > ModeCTR:
+		return &ContainerError{Reason: "неизвестный режим " + strconv.Itoa(int(c.Mode))}
+	case c.Padding > PaddingZero:
+		return &ContainerError{Reason: "неизвестная схема дополнения " + strconv.Itoa(int(c.Padding))}
+	case c.Rounds < 1 || c.Rounds > MaxRounds:
+		return &ContainerError{Reason: "число раундов " + strconv.Itoa(int(c.Rounds))}
+	case len(c.IV) != BlockSize:
+		return &ContainerError{Reason: "длина вектора инициализации " + strconv.Itoa(len(c.IV)) + " байт"}
+	}
+	return c.KDF.validate()
+}
+
+// containerReader последовательно читает поля заголовка, запоминая первую ошибку чтения
+type containerReader struct {
+	r   io.Reader
+	err error
+}
+
+// readBytes читает n байт
+func (r *containerReader) readBytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		r.err = err
+		return nil
+	}
+	return b
+}
+
+// readByte читает один байт
+func (r *containerReader) readByte() byte {
+	if b := r.readBytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// readUint32 читает 4-байтовое число
+func (r *containerReader) readUint32() uint32 {
+	if b := r.readBytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// Seal шифрует данные и упаковывает результат в контейнер вместе с параметрами шифрования.
+// Для каждого вызова вырабатывается новый случайный вектор инициализации, он сохраняется в заголовке.
+// Если задана функция выработки ключа (WithKDF), key считается паролем, а ключ вырабатывается
+// из него с новой случайной солью, которая вместе с параметрами функции также сохраняется в заголовке
+func (d *MyDES) Seal(plain []byte, key string) ([]byte, error) {
+	var buf bytes.Buffer
+	e, err := d.NewEncrypter(&buf, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.Write(plain); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Open распаковывает контейнер и расшифровывает его. Алгоритм, режим, дополнение, вектор
+// инициализации и параметры выработки ключа берутся из заголовка контейнера, а не из настроек MyDES.
+// Таблицы DES и число раундов должны совпадать с заданными WithVariant и WithRounds, иначе возвращается *MismatchError.
+// Если контейнер содержит код аутентичности, открытый текст возвращается только после его проверки;
+// при включенном WithMAC контейнер без кода отклоняется. Нарушение целостности возвращается как *IntegrityError
+func (d *MyDES) Open(data []byte, key string) ([]byte, error) {
+	r, err := d.NewDecrypter(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ошибка целостности обнаруживается в конце потока, поэтому при ошибке открытый текст отбрасывается целиком
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
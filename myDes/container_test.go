@@ -0,0 +1,232 @@
+package myDes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestSealOpen проверяет упаковку в контейнер и распаковку для всех алгоритмов и режимов
+func TestSealOpen(t *testing.T) {
+	plain := []byte("Контейнер хранит параметры шифрования\x00\x00")
+	keys := map[Algorithm]string{AlgorithmDES: "Secret_8", AlgorithmTripleDES: "Super_Secret_key"}
+	for _, algorithm := range []Algorithm{AlgorithmDES, AlgorithmTripleDES} {
+		key := keys[algorithm]
+		for _, mode := range []Mode{ModeCBC, ModeECB, ModeCFB8, ModeCFB64, ModeOFB, ModeCTR} {
+			sealed, err := NewMyDES("01234567", WithAlgorithm(algorithm), WithMode(mode)).Seal(plain, key)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", algorithm, mode, err)
+			}
+
+			// Параметры берутся из заголовка, поэтому для распаковки подойдет MyDES с любыми настройками
+			got, err := NewMyDES("").Open(sealed, key)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", algorithm, mode, err)
+			}
+			if !bytes.Equal(got, plain) {
+				t.Fatalf("%s/%s: получено %q", algorithm, mode, got)
+			}
+		}
+	}
+}
+
+// TestContainerRoundTrip проверяет запись и чтение всех полей контейнера
+func TestContainerRoundTrip(t *testing.T) {
+	c := Container{
+		Algorithm:  AlgorithmTripleDES,
+		Mode:       ModeCTR,
+		Padding:    PaddingISO7816,
+		Variant:    [8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Rounds:     12,
+		IV:         []byte("01234567"),
+		CipherText: []byte("ciphertext"),
+		MAC:        []byte("0123456789abcdef"),
+	}
+	data, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Container
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.Algorithm != c.Algorithm || got.Mode != c.Mode || got.Padding != c.Padding || got.Variant != c.Variant || got.Rounds != c.Rounds ||
+		!bytes.Equal(got.IV, c.IV) || !bytes.Equal(got.CipherText, c.CipherText) || !bytes.Equal(got.MAC, c.MAC) {
+		t.Fatalf("прочитано %+v, ожидалось %+v", got, c)
+	}
+	if !bytes.Equal(data[:len(c.Header())], c.Header()) {
+		t.Fatal("заголовок не совпадает с началом контейнера")
+	}
+}
+
+// TestContainerErrors проверяет отказ от поврежденных контейнеров
+func TestContainerErrors(t *testing.T) {
+	sealed, err := NewMyDES("01234567").Seal([]byte("some text"), "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := func(offset int, value byte) []byte {
+		data := append([]byte(nil), sealed...)
+		data[offset] = value
+		return data
+	}
+
+	var (
+		containerErr *ContainerError
+		lengthErr    *BlockLengthError
+	)
+	tests := []struct {
+		name string
+		data []byte
+		want any
+	}{
+		{"неизвестная версия", corrupt(4, 9), &containerErr},
+		{"неизвестный алгоритм", corrupt(5, 7), &containerErr},
+		{"неизвестный режим", corrupt(6, 42), &containerErr},
+		{"нулевое число раундов", corrupt(16, 0), &containerErr},
+		{"обрезанный заголовок", sealed[:10], &containerErr},
+		{"неполный блок", sealed[:len(sealed)-3], &lengthErr},
+	}
+	for _, tt := range tests {
+		if _, err := NewMyDES("").Open(tt.data, "12345678"); !errors.As(err, tt.want) {
+			t.Errorf("%s: %v, ожидалась ошибка типа %T", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := NewMyDES("").Open([]byte("0x1234"), "12345678"); !errors.Is(err, ErrNotContainer) {
+		t.Errorf("шестнадцатеричный шифротекст: %v, ожидалась ErrNotContainer", err)
+	}
+}
+
+// TestSealRandomIV проверяет, что одинаковые сообщения шифруются с разными векторами инициализации
+func TestSealRandomIV(t *testing.T) {
+	d := NewMyDES("01234567")
+	first, err := d.Seal([]byte("одинаковый открытый текст"), "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := d.Seal([]byte("одинаковый открытый текст"), "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var a, b Container
+	if err := a.UnmarshalBinary(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UnmarshalBinary(second); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a.IV, b.IV) || bytes.Equal(a.CipherText[:BlockSize], b.CipherText[:BlockSize]) {
+		t.Fatal("два сообщения зашифрованы с одинаковым вектором инициализации")
+	}
+	if bytes.Equal(a.IV, []byte("01234567")) {
+		t.Fatal("Seal использует вектор инициализации из конструктора")
+	}
+}
+
+// TestSealOpenMAC проверяет, что контейнер с кодом аутентичности отклоняется при любом изменении
+func TestSealOpenMAC(t *testing.T) {
+	d := NewMyDES("", WithMAC())
+	sealed, err := d.Seal([]byte("аутентифицированное сообщение"), "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, err := d.Open(sealed, "12345678"); err != nil || string(plain) != "аутентифицированное сообщение" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+
+	var c Container
+	if err := c.UnmarshalBinary(sealed); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.MAC) != macSize {
+		t.Fatalf("длина кода аутентичности %d, ожидалось %d", len(c.MAC), macSize)
+	}
+	flip := func(offset int) []byte {
+		data := append([]byte(nil), sealed...)
+		data[offset] ^= 1
+		return data
+	}
+	stripped := c
+	stripped.MAC = nil
+	withoutMAC, err := stripped.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headerLen := len(c.Header())
+	tests := []struct {
+		name string
+		data []byte
+		key  string
+	}{
+		{"измененный вектор инициализации", flip(len(ContainerMagic) + 14), "12345678"},
+		{"измененный шифротекст", flip(headerLen), "12345678"},
+		{"измененный код", flip(len(sealed) - 1), "12345678"},
+		{"обрезанный файл", sealed[:len(sealed)-3], "12345678"},
+		{"удаленный код", withoutMAC, "12345678"},
+		{"неверный ключ", sealed, "87654321"},
+	}
+	for _, tt := range tests {
+		var integrityErr *IntegrityError
+		plain, err := d.Open(tt.data, tt.key)
+		if !errors.As(err, &integrityErr) {
+			t.Errorf("%s: %v, ожидалась ошибка целостности", tt.name, err)
+		}
+		if plain != nil {
+			t.Errorf("%s: возвращен открытый текст %q", tt.name, plain)
+		}
+	}
+
+	// Шифрование использует ключ, выработанный из основного: без кода основной ключ текст не расшифровывает
+	if plain, err := NewMyDES("").Open(withoutMAC, "12345678"); err == nil && string(plain) == "аутентифицированное сообщение" {
+		t.Error("шифротекст расшифрован основным ключом")
+	}
+}
+
+// TestOpenMismatch проверяет, что контейнер не открывается с другими таблицами или другим числом раундов,
+// даже если их идентификаторы в заголовке подменены
+func TestOpenMismatch(t *testing.T) {
+	tables := StandardTables()
+	tables.SBoxes[0], tables.SBoxes[7] = tables.SBoxes[7], tables.SBoxes[0]
+	variant, err := NewVariant(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := StandardTables()
+	renamed.Name = "renamed"
+
+	plain := []byte("параметры сети Фейстеля")
+	sealed, err := NewMyDES("", WithVariant(variant), WithRounds(8), WithMAC()).Seal(plain, "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mismatchErr *MismatchError
+	for name, d := range map[string]*MyDES{
+		"таблицы стандарта": NewMyDES("", WithRounds(8), WithMAC()),
+		"16 раундов":        NewMyDES("", WithVariant(variant), WithMAC()),
+	} {
+		if _, err := d.Open(sealed, "12345678"); !errors.As(err, &mismatchErr) {
+			t.Errorf("%s: %v, ожидалась *MismatchError", name, err)
+		}
+	}
+	if got, err := NewMyDES("", WithVariant(variant), WithRounds(8), WithMAC()).Open(sealed, "12345678"); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Open = %q, %v", got, err)
+	}
+
+	// Одинаковые таблицы под разными названиями имеют один идентификатор
+	if mustVariant(renamed).ID() != (*Variant)(nil).ID() {
+		t.Error("идентификатор зависит от названия таблиц")
+	}
+
+	// Заголовок входит в код аутентичности: подмена числа раундов в нем обнаруживается
+	forged := append([]byte(nil), sealed...)
+	forged[16] = 16
+	var integrityErr *IntegrityError
+	if _, err := NewMyDES("", WithVariant(variant), WithMAC()).Open(forged, "12345678"); !errors.As(err, &integrityErr) {
+		t.Errorf("подмененное число раундов: %v, ожидалась ошибка целостности", err)
+	}
+}
@@ -1,481 +1,565 @@
 package myDes
 
 import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
-	"math/big"
 	"strconv"
 	"strings"
 )
 
-// MyDES представляет собой алгоритм шифрования/дешифрования DES
-type MyDES struct {
-	childKeys []string // Массив для хранения подключей
-	iv        string   // Вектор инициализации
+// Таблица начальной перестановки (IP)
+var initReplaceTable = []int{
+	58, 50, 42, 34, 26, 18, 10, 2,
+	60, 52, 44, 36, 28, 20, 12, 4,
+	62, 54, 46, 38, 30, 22, 14, 6,
+	64, 56, 48, 40, 32, 24, 16, 8,
+	57, 49, 41, 33, 25, 17, 9, 1,
+	59, 51, 43, 35, 27, 19, 11, 3,
+	61, 53, 45, 37, 29, 21, 13, 5,
+	63, 55, 47, 39, 31, 23, 15, 7,
 }
 
-// NewMyDES инициализирует новый экземпляр MyDES с заданным вектором инициализации
-func NewMyDES(iv string) *MyDES {
-	return &MyDES{
-		iv: iv,
-	}
+// Таблица расширения, определяющая порядок выбора битов из 32-битного блока
+var extendTable = []int{
+	32, 1, 2, 3, 4, 5,
+	4, 5, 6, 7, 8, 9,
+	8, 9, 10, 11, 12, 13,
+	12, 13, 14, 15, 16, 17,
+	16, 17, 18, 19, 20, 21,
+	20, 21, 22, 23, 24, 25,
+	24, 25, 26, 27, 28, 29,
+	28, 29, 30, 31, 32, 1,
+}
+
+// Таблица замены P-Box для DES
+var pBoxReplaceTable = []int{
+	16, 7, 20, 21, 29, 12, 28, 17, 1, 15, 23, 26, 5, 18, 31, 10,
+	2, 8, 24, 14, 32, 27, 3, 9, 19, 13, 30, 6, 22, 11, 4, 25,
+}
+
+// Таблица для замены битов в ключе (PC-1)
+var keyReplaceTable = []int{
+	57, 49, 41, 33, 25, 17, 9, 1, 58, 50, 42, 34, 26, 18,
+	10, 2, 59, 51, 43, 35, 27, 19, 11, 3, 60, 52, 44, 36,
+	63, 55, 47, 39, 31, 23, 15, 7, 62, 54, 46, 38, 30, 22,
+	14, 6, 61, 53, 45, 37, 29, 21, 13, 5, 28, 20, 12, 4,
 }
 
-// bitEncode преобразует строку в бинарное представление (01)
-func (d *MyDES) bitEncode(s string) string {
-	binStr := ""
-	for _, c := range []byte(s) {
-		// Преобразование каждого символа в 8-битное бинарное представление и добавление к binStr
-		binStr += fmt.Sprintf("%08b", c)
+// Таблица для выборочной перестановки битов в подключе (PC-2)
+var keySelectTable = []int{
+	14, 17, 11, 24, 1, 5, 3, 28, 15, 6, 21, 10,
+	23, 19, 12, 4, 26, 8, 16, 7, 27, 20, 13, 2,
+	41, 52, 31, 37, 47, 55, 30, 40, 51, 45, 33, 48,
+	44, 49, 39, 56, 34, 53, 46, 42, 50, 36, 29, 32,
+}
+
+// Таблица для вращения ключа (суммарный сдвиг половин ключа для каждого раунда)
+var spinTable = []int{1, 2, 4, 6, 8, 10, 12, 14, 15, 17, 19, 21, 23, 25, 27, 28}
+
+// Таблица S-Box, представленная как трехмерный массив
+var sBoxTable = [8][4][16]uint8{
+	{
+		{14, 4, 13, 1, 2, 15, 11, 8, 3, 10, 6, 12, 5, 9, 0, 7},
+		{0, 15, 7, 4, 14, 2, 13, 1, 10, 6, 12, 11, 9, 5, 3, 8},
+		{4, 1, 14, 8, 13, 6, 2, 11, 15, 12, 9, 7, 3, 10, 5, 0},
+		{15, 12, 8, 2, 4, 9, 1, 7, 5, 11, 3, 14, 10, 0, 6, 13},
+	},
+	{
+		{15, 1, 8, 14, 6, 11, 3, 4, 9, 7, 2, 13, 12, 0, 5, 10},
+		{3, 13, 4, 7, 15, 2, 8, 14, 12, 0, 1, 10, 6, 9, 11, 5},
+		{0, 14, 7, 11, 10, 4, 13, 1, 5, 8, 12, 6, 9, 3, 2, 15},
+		{13, 8, 10, 1, 3, 15, 4, 2, 11, 6, 7, 12, 0, 5, 14, 9},
+	},
+	{
+		{10, 0, 9, 14, 6, 3, 15, 5, 1, 13, 12, 7, 11, 4, 2, 8},
+		{13, 7, 0, 9, 3, 4, 6, 10, 2, 8, 5, 14, 12, 11, 15, 1},
+		{13, 6, 4, 9, 8, 15, 3, 0, 11, 1, 2, 12, 5, 10, 14, 7},
+		{1, 10, 13, 0, 6, 9, 8, 7, 4, 15, 14, 3, 11, 5, 2, 12},
+	},
+	{
+		{7, 13, 14, 3, 0, 6, 9, 10, 1, 2, 8, 5, 11, 12, 4, 15},
+		{13, 8, 11, 5, 6, 15, 0, 3, 4, 7, 2, 12, 1, 10, 14, 9},
+		{10, 6, 9, 0, 12, 11, 7, 13, 15, 1, 3, 14, 5, 2, 8, 4},
+		{3, 15, 0, 6, 10, 1, 13, 8, 9, 4, 5, 11, 12, 7, 2, 14},
+	},
+	{
+		{2, 12, 4, 1, 7, 10, 11, 6, 8, 5, 3, 15, 13, 0, 14, 9},
+		{14, 11, 2, 12, 4, 7, 13, 1, 5, 0, 15, 10, 3, 9, 8, 6},
+		{4, 2, 1, 11, 10, 13, 7, 8, 15, 9, 12, 5, 6, 3, 0, 14},
+		{11, 8, 12, 7, 1, 14, 2, 13, 6, 15, 0, 9, 10, 4, 5, 3},
+	},
+	{
+		{12, 1, 10, 15, 9, 2, 6, 8, 0, 13, 3, 4, 14, 7, 5, 11},
+		{10, 15, 4, 2, 7, 12, 9, 5, 6, 1, 13, 14, 0, 11, 3, 8},
+		{9, 14, 15, 5, 2, 8, 12, 3, 7, 0, 4, 10, 1, 13, 11, 6},
+		{4, 3, 2, 12, 9, 5, 15, 10, 11, 14, 1, 7, 6, 0, 8, 13},
+	},
+	{
+		{4, 11, 2, 14, 15, 0, 8, 13, 3, 12, 9, 7, 5, 10, 6, 1},
+		{13, 0, 11, 7, 4, 9, 1, 10, 14, 3, 5, 12, 2, 15, 8, 6},
+		{1, 4, 11, 13, 12, 3, 7, 14, 10, 15, 6, 8, 0, 5, 9, 2},
+		{6, 11, 13, 8, 1, 4, 10, 7, 9, 5, 0, 15, 14, 2, 3, 12},
+	},
+	{
+		{13, 2, 8, 4, 6, 15, 11, 1, 10, 9, 3, 14, 5, 0, 12, 7},
+		{1, 15, 13, 8, 10, 3, 7, 4, 12, 5, 6, 11, 0, 14, 9, 2},
+		{7, 11, 4, 1, 9, 12, 14, 2, 0, 6, 10, 13, 15, 3, 5, 8},
+		{2, 1, 14, 7, 4, 10, 8, 13, 15, 12, 9, 0, 3, 5, 6, 11},
+	},
+}
+
+// permutation - предвычисленная перестановка битов: для каждого байта входа
+// и каждого его значения хранится уже переставленный вклад этого байта в результат
+type permutation [][256]uint64
+
+// newPermutation строит предвычисленную перестановку по таблице замены для блока из size бит
+func newPermutation(replaceTable []int, size int) permutation {
+	p := make(permutation, size/8)
+	for i := range p {
+		for v := 0; v < 256; v++ {
+			// Ставим значение байта на его место во входном блоке и переставляем только его биты
+			p[i][v] = Permute(uint64(v)<<(size-8*(i+1)), replaceTable, size)
+		}
 	}
-	return binStr
+	return p
 }
 
-// bitDecode преобразует список бинарных строк обратно в исходную строку
-func (d *MyDES) bitDecode(s []string) string {
-	decoded := ""
-	for _, binStr := range s {
-		// Преобразование каждой бинарной строки в целое число и далее в символ
-		val, _ := strconv.ParseInt(binStr, 2, 64)
-		decoded += string(val)
+// apply выполняет перестановку блока, объединяя вклады всех его байтов
+func (p permutation) apply(block uint64) uint64 {
+	size := len(p) * 8
+	var result uint64
+	for i := range p {
+		result |= p[i][byte(block>>(size-8*(i+1)))]
 	}
-	return decoded
+	return result
 }
 
-// negate инвертирует бинарную строку
-func (d *MyDES) negate(s string) string {
-	result := ""
-	for _, i := range s {
-		// Инверсия каждого бита в бинарной строке
-		if i == '1' {
-			result += "0"
-		} else {
-			result += "1"
+// newSBoxLookup переводит таблицу S-Box в вид, индексируемый сразу 6-битным входом
+func newSBoxLookup(table [8][4][16]uint8) [8][64]uint8 {
+	var lookup [8][64]uint8
+	for i := range table {
+		for six := 0; six < 64; six++ {
+			// Строка задается крайними битами, столбец - четырьмя средними
+			row := (six>>4)&2 | six&1
+			line := (six >> 1) & 0xf
+			lookup[i][six] = table[i][row][line]
 		}
 	}
-	return result
+	return lookup
 }
 
-// replaceBlock заменяет отдельные биты блока на основе таблицы замены
-func (d *MyDES) replaceBlock(block string, replaceTable []int) string {
-	result := ""
+// Permute переставляет биты блока из size бит по таблице: i-й бит результата - это бит table[i] блока.
+// Биты нумеруются с единицы, начиная со старшего, как в стандарте DES. Таблица может повторять биты,
+// как таблица расширения E
+func Permute(block uint64, replaceTable []int, size int) uint64 {
+	var result uint64
 	for _, i := range replaceTable {
 		// Замена битов в блоке согласно указанным позициям в таблице замены
-		result += string(block[i-1])
+		result = result<<1 | (block>>(size-i))&1
 	}
 	return result
 }
 
-// processingEncodeInput преобразует входную строку в бинарную форму и разбивает ее на блоки по 64 бита
-func (d *MyDES) processingEncodeInput(input string) []string {
-	result := make([]string, 0)
-	bitString := d.bitEncode(input)
+// keySchedule - 16 подключей раундов по 48 бит. Вычисляется один раз для ключа и после этого не изменяется
+type keySchedule [16]uint64
 
-	// Если длина бинарной строки не кратна 64, добавляем нули для выравнивания
-	/*if len(bitString)%64 != 0 {
-		for i := 0; i < 64-len(bitString)%64; i++ {
-			bitString += "0"
-		}
-	}*/
+// MyDES представляет собой алгоритм шифрования/дешифрования DES.
+// Методы MyDES не изменяют его состояния, поэтому один экземпляр можно использовать из нескольких горутин
+type MyDES struct {
+	iv        string       // Вектор инициализации для Encode и Decode (Seal вырабатывает случайный)
+	schedule  *keySchedule // Подключи для работы в качестве cipher.Block (см. NewCipher)
+	algorithm Algorithm    // Блочный шифр, используемый в Encode и Decode
+	mode      Mode         // Режим работы блочного шифра
+	padding   Padding      // Схема дополнения для режимов ECB и CBC
+	kdf       KDFParams    // Функция выработки ключа из пароля для Seal (соль вырабатывается при шифровании)
+	mac       bool         // Добавлять в Seal и требовать в Open код аутентичности
+	workers   int          // Число горутин для режимов ECB и CTR (0 - по числу процессоров)
+
+	keyValidation bool     // Отклонять слабые ключи в CheckKey, Seal и Open
+	parity        Parity   // Проверка четности ключа при keyValidation
+	variant       *Variant // Таблицы варианта DES (nil - таблицы стандарта)
+	rounds        int      // Число раундов сети Фейстеля (0 - 16 раундов стандарта)
+}
 
-	padding := 64 - len(bitString)%64
-	if padding != 64 {
-		bitString += strings.Repeat("0", padding)
+// NewMyDES инициализирует новый экземпляр MyDES с заданным вектором инициализации и параметрами
+func NewMyDES(iv string, opts ...Option) *MyDES {
+	d := &MyDES{
+		iv: iv,
 	}
-
-	// Разбиваем бинарную строку на блоки по 64 бита
-	for i := 0; i < len(bitString)/64; i++ {
-		result = append(result, bitString[i*64:i*64+64])
+	for _, opt := range opts {
+		opt(d)
 	}
-	return result
+	return d
 }
 
-// processingDecodeInput преобразует входную строку в шестнадцатеричной форме в бинарную и разбивает ее на блоки по 64 бита
-func (d *MyDES) processingDecodeInput(enter interface{}) []string {
-	result := make([]string, 0)
-	var inputList []string
+// CheckKey проверяет, что строковый ключ допустим для выбранного алгоритма: для DES ключ должен
+// состоять ровно из 8 байт, для Triple DES - из 16 или 24 байт с допустимым вариантом ключей.
+// С WithKeyValidation дополнительно отклоняются слабые ключи и, при необходимости, ключи с неверной четностью.
+// Encode и Decode эту проверку не выполняют и, как и раньше, дополняют или обрезают ключ
+func (d *MyDES) CheckKey(key string) error {
+	_, err := d.checkKey(key, false)
+	return err
+}
 
-	switch v := enter.(type) {
-	case string:
-		inputList = strings.Split(v, "0x")[1:]
-	case []byte:
-		inputList = strings.Split(string(v), "0x")[1:]
-	default:
-		panic("Unsupported input type")
+// checkKey выполняет CheckKey и возвращает ключ, с которым нужно шифровать: при WithKeyValidation(ParityFix) -
+// ключ с исправленными битами четности, иначе исходный. DES биты четности не использует, но от них зависят
+// ключи контейнера, выработанные из основного ключа, поэтому ключи с разной четностью дают один контейнер.
+// У ключей, выработанных из пароля (derived), четность не проверяется: функция выработки ключа ее не обеспечивает
+func (d *MyDES) checkKey(key string, derived bool) (string, error) {
+	if err := d.checkRounds(); err != nil {
+		return "", err
 	}
 
-	for _, i := range inputList {
-		decoded, err := strconv.ParseUint(i, 16, 64)
-		if err != nil {
-			panic(err)
-		}
-
-		binData := fmt.Sprintf("%064b", decoded)
-		result = append(result, binData)
+	var err error
+	if d.algorithm == AlgorithmTripleDES {
+		_, err = NewTripleDES([]byte(key))
+	} else {
+		_, err = NewCipher([]byte(key))
+	}
+	if err != nil {
+		return "", err
+	}
+	if !d.keyValidation {
+		return key, nil
 	}
 
-	return result
+	parity := d.parity
+	if derived {
+		parity = ParityIgnore
+	}
+	checked, err := d.variant.ValidateKey([]byte(key), parity)
+	if err != nil {
+		return "", err
+	}
+	return string(checked), nil
 }
 
-// keyConversion преобразует исходный 64-битный ключ в 56-битный ключ и выполняет замену
-func (d *MyDES) keyConversion(key string) string {
-	// Преобразование ключа в бинарную строку
-	key = d.bitEncode(key)
-
-	// Добавление нулей до достижения 64 бит
-	for len(key) < 64 {
-		key += "0"
+// checkRounds проверяет число раундов, заданное WithRounds
+func (d *MyDES) checkRounds() error {
+	if d.rounds < 0 || d.rounds > MaxRounds {
+		return RoundsError(d.rounds)
 	}
-	firstKey := key[:64]
+	return nil
+}
 
-	// Таблица для замены битов в ключе
-	keyReplaceTable := []int{
-		57, 49, 41, 33, 25, 17, 9, 1, 58, 50, 42, 34, 26, 18,
-		10, 2, 59, 51, 43, 35, 27, 19, 11, 3, 60, 52, 44, 36,
-		63, 55, 47, 39, 31, 23, 15, 7, 62, 54, 46, 38, 30, 22,
-		14, 6, 61, 53, 45, 37, 29, 21, 13, 5, 28, 20, 12, 4,
+// newBlock создает блочный шифр выбранного алгоритма и варианта таблиц для строкового ключа.
+// Недопустимое число раундов вызывает панику с RoundsError: шифр без раундов не создается
+func (d *MyDES) newBlock(key string) cipher.Block {
+	if err := d.checkRounds(); err != nil {
+		panic(err)
 	}
-
-	// Замена битов в ключе согласно таблице
-	return d.replaceBlock(firstKey, keyReplaceTable)
+	if d.algorithm == AlgorithmTripleDES {
+		k := tripleKey(key)
+		if len(k) == 2*BlockSize {
+			return newTripleDES(k[:8], k[8:], k[:8], d.variant, d.rounds)
+		}
+		return newTripleDES(k[:8], k[8:16], k[16:], d.variant, d.rounds)
+	}
+	// Для одинарного DES ключ дополняется нулями или обрезается до 8 байт в keyConversion
+	return newDESBlock(key, d.variant, d.rounds)
 }
 
-// spinKey выполняет вращение для генерации подключей
-func (d *MyDES) spinKey(key string) []string {
-	// Получение 56-битного ключа после замены
-	kc := d.keyConversion(key)
-	first, second := kc[:28], kc[28:]
-
-	// Таблица для вращения ключа
-	spinTable := []int{1, 2, 4, 6, 8, 10, 12, 14, 15, 17, 19, 21, 23, 25, 27, 28}
-	subKeys := make([]string, 16)
+// newDESBlock создает одинарный DES с таблицами варианта variant (nil - стандарт) и числом раундов rounds
+// (0 - 16), вычисляя подключи один раз
+func newDESBlock(key string, variant *Variant, rounds int) *MyDES {
+	d := &MyDES{variant: variant, rounds: rounds}
+	schedule := d.keySelectionReplacement(key)
+	d.schedule = &schedule
+	return d
+}
 
-	// Выполнение вращения и создание 16 подключей
-	for i := 1; i < 17; i++ {
-		firstAfterSpin := first[spinTable[i-1]:] + first[:spinTable[i-1]]
-		secondAfterSpin := second[spinTable[i-1]:] + second[:spinTable[i-1]]
-		subKeys[i-1] = firstAfterSpin + secondAfterSpin
+// tripleKey дополняет строковый ключ нулями до 16 байт, а если он длиннее - до 24 байт (лишнее отбрасывается)
+func tripleKey(key string) []byte {
+	size := 2 * BlockSize
+	if len(key) > size {
+		size = 3 * BlockSize
 	}
-	return subKeys
+	k := make([]byte, size)
+	copy(k, key)
+	return k
 }
 
-// keySelectionReplacement получает подключ в 48 бит путем выборочной перестановки
-func (d *MyDES) keySelectionReplacement(key string) {
-	// Обнуление массива подключей
-	d.childKeys = nil
-
-	// Таблица для выборочной перестановки битов в подключе
-	keySelectTable := []int{
-		14, 17, 11, 24, 1, 5, 3, 28, 15, 6, 21, 10,
-		23, 19, 12, 4, 26, 8, 16, 7, 27, 20, 13, 2,
-		41, 52, 31, 37, 47, 55, 30, 40, 51, 45, 33, 48,
-		44, 49, 39, 56, 34, 53, 46, 42, 50, 36, 29, 32,
+// cryptUint64 шифрует или расшифровывает один 64-битный блок блочным шифром
+func cryptUint64(b cipher.Block, block uint64, isDecode bool) uint64 {
+	var buf [BlockSize]byte
+	binary.BigEndian.PutUint64(buf[:], block)
+	if isDecode {
+		b.Decrypt(buf[:], buf[:])
+	} else {
+		b.Encrypt(buf[:], buf[:])
 	}
+	return binary.BigEndian.Uint64(buf[:])
+}
 
-	// Генерация подключей
-	subKeys := d.spinKey(key)
+// bitEncode преобразует первые 8 байт строки в 64-битный блок, дополняя недостающие байты нулями
+func (d *MyDES) bitEncode(s string) uint64 {
+	var buf [8]byte
+	copy(buf[:], s)
+	return binary.BigEndian.Uint64(buf[:])
+}
 
-	// Добавление подключей после выборочной перестановки
-	for _, childKey56 := range subKeys {
-		d.childKeys = append(d.childKeys, d.replaceBlock(childKey56, keySelectTable))
-	}
+// bitDecode преобразует расшифрованные байты обратно в исходную строку без изменений
+func (d *MyDES) bitDecode(data []byte) string {
+	return string(data)
 }
 
-// initReplaceBlock выполняет начальную блочную перестановку
-func (d *MyDES) initReplaceBlock(block string) string {
-	// Таблица для начальной блочной перестановки
-	replaceTable := []int{
-		58, 50, 42, 34, 26, 18, 10, 2,
-		60, 52, 44, 36, 28, 20, 12, 4,
-		62, 54, 46, 38, 30, 22, 14, 6,
-		64, 56, 48, 40, 32, 24, 16, 8,
-		57, 49, 41, 33, 25, 17, 9, 1,
-		59, 51, 43, 35, 27, 19, 11, 3,
-		61, 53, 45, 37, 29, 21, 13, 5,
-		63, 55, 47, 39, 31, 23, 15, 7,
+// processingEncodeInput подготавливает входную строку к шифрованию.
+// Для блочных режимов данные дополняются до длины, кратной 64 битам, по выбранной схеме
+func (d *MyDES) processingEncodeInput(input string) []byte {
+	if d.mode.IsStream() {
+		return []byte(input)
 	}
-
-	// Выполнение блочной перестановки
-	return d.replaceBlock(block, replaceTable)
+	return d.padding.pad([]byte(input))
 }
 
-// endReplaceBlock выполняет конечную блочную перестановку
-func (d *MyDES) endReplaceBlock(block string) string {
-	// Таблица для конечной блочной перестановки
-	replaceTable := []int{
-		40, 8, 48, 16, 56, 24, 64, 32,
-		39, 7, 47, 15, 55, 23, 63, 31,
-		38, 6, 46, 14, 54, 22, 62, 30,
-		37, 5, 45, 13, 53, 21, 61, 29,
-		36, 4, 44, 12, 52, 20, 60, 28,
-		35, 3, 43, 11, 51, 19, 59, 27,
-		34, 2, 42, 10, 50, 18, 58, 26,
-		33, 1, 41, 9, 49, 17, 57, 25,
-	}
-
-	// Выполнение блочной перестановки
-	return d.replaceBlock(block, replaceTable)
-}
-
-// blockExtend расширяет блок с использованием расширения
-func (d *MyDES) blockExtend(block string) string {
-	extendedBlock := ""   // Инициализируем пустую строку, в которую будем добавлять расширенные биты блока
-	extendTable := []int{ // Таблица расширения, определяющая порядок выбора битов из блока
-		32, 1, 2, 3, 4, 5,
-		4, 5, 6, 7, 8, 9,
-		8, 9, 10, 11, 12, 13,
-		12, 13, 14, 15, 16, 17,
-		16, 17, 18, 19, 20, 21,
-		20, 21, 22, 23, 24, 25,
-		24, 25, 26, 27, 28, 29,
-		28, 29, 30, 31, 32, 1,
-	}
-
-	// Проходим по каждому индексу в таблице и добавляем соответствующий бит из блока в расширенный блок
-	for _, i := range extendTable {
-		extendedBlock += string(block[i-1])
-	}
-
-	return extendedBlock // Возвращаем расширенный блок
-}
-
-// notOr выполняет операцию XOR двух бинарных строк (01)
-func (d *MyDES) notOr(a, b string) string {
-	size := len(a) // Получаем длину одной из строк, предполагая, что длины обеих строк равны
-	result := ""   // Инициализируем строку для хранения результата XOR
-
-	// Проходим по каждому биту в строках и выполняем XOR, добавляя результат в строку результата
-	for i := 0; i < size; i++ {
-		if a[i] == b[i] {
-			result += "0"
+// processingEncodeOutput записывает шифротекст в шестнадцатеричной форме, по блоку на каждый префикс 0x.
+// В блочных режимах ведущие нули блоков опускаются, в потоковых каждый байт записывается двумя цифрами,
+// чтобы при расшифровке можно было восстановить длину последнего неполного блока
+func (d *MyDES) processingEncodeOutput(data []byte) string {
+	var result strings.Builder
+	for i := 0; i < len(data); i += BlockSize {
+		if d.mode.IsStream() {
+			fmt.Fprintf(&result, "0x%X", data[i:min(i+BlockSize, len(data))])
 		} else {
-			result += "1"
+			result.WriteString(binaryToHex(binary.BigEndian.Uint64(data[i:])))
 		}
 	}
-
-	return result // Возвращаем результат операции XOR
+	return result.String()
 }
 
-// sBoxReplace выполняет подстановку S-Box, преобразуя входные 48 бит в выходные 32 бита
-func (d *MyDES) sBoxReplace(block48 string) string {
-	// Таблица S-Box, представленная как двумерный массив
-	sBoxTable := [8][4][16]int{
-		{
-			{14, 4, 13, 1, 2, 15, 11, 8, 3, 10, 6, 12, 5, 9, 0, 7},
-			{0, 15, 7, 4, 14, 2, 13, 1, 10, 6, 12, 11, 9, 5, 3, 8},
-			{4, 1, 14, 8, 13, 6, 2, 11, 15, 12, 9, 7, 3, 10, 5, 0},
-			{15, 12, 8, 2, 4, 9, 1, 7, 5, 11, 3, 14, 10, 0, 6, 13},
-		},
-		{
-			{15, 1, 8, 14, 6, 11, 3, 4, 9, 7, 2, 13, 12, 0, 5, 10},
-			{3, 13, 4, 7, 15, 2, 8, 14, 12, 0, 1, 10, 6, 9, 11, 5},
-			{0, 14, 7, 11, 10, 4, 13, 1, 5, 8, 12, 6, 9, 3, 2, 15},
-			{13, 8, 10, 1, 3, 15, 4, 2, 11, 6, 7, 12, 0, 5, 14, 9},
-		},
-		{
-			{10, 0, 9, 14, 6, 3, 15, 5, 1, 13, 12, 7, 11, 4, 2, 8},
-			{13, 7, 0, 9, 3, 4, 6, 10, 2, 8, 5, 14, 12, 11, 15, 1},
-			{13, 6, 4, 9, 8, 15, 3, 0, 11, 1, 2, 12, 5, 10, 14, 7},
-			{1, 10, 13, 0, 6, 9, 8, 7, 4, 15, 14, 3, 11, 5, 2, 12},
-		},
-		{
-			{7, 13, 14, 3, 0, 6, 9, 10, 1, 2, 8, 5, 11, 12, 4, 15},
-			{13, 8, 11, 5, 6, 15, 0, 3, 4, 7, 2, 12, 1, 10, 14, 9},
-			{10, 6, 9, 0, 12, 11, 7, 13, 15, 1, 3, 14, 5, 2, 8, 4},
-			{3, 15, 0, 6, 10, 1, 13, 8, 9, 4, 5, 11, 12, 7, 2, 14},
-		},
-		{
-			{2, 12, 4, 1, 7, 10, 11, 6, 8, 5, 3, 15, 13, 0, 14, 9},
-			{14, 11, 2, 12, 4, 7, 13, 1, 5, 0, 15, 10, 3, 9, 8, 6},
-			{4, 2, 1, 11, 10, 13, 7, 8, 15, 9, 12, 5, 6, 3, 0, 14},
-			{11, 8, 12, 7, 1, 14, 2, 13, 6, 15, 0, 9, 10, 4, 5, 3},
-		},
-		{
-			{12, 1, 10, 15, 9, 2, 6, 8, 0, 13, 3, 4, 14, 7, 5, 11},
-			{10, 15, 4, 2, 7, 12, 9, 5, 6, 1, 13, 14, 0, 11, 3, 8},
-			{9, 14, 15, 5, 2, 8, 12, 3, 7, 0, 4, 10, 1, 13, 11, 6},
-			{4, 3, 2, 12, 9, 5, 15, 10, 11, 14, 1, 7, 6, 0, 8, 13},
-		},
-		{
-			{4, 11, 2, 14, 15, 0, 8, 13, 3, 12, 9, 7, 5, 10, 6, 1},
-			{13, 0, 11, 7, 4, 9, 1, 10, 14, 3, 5, 12, 2, 15, 8, 6},
-			{1, 4, 11, 13, 12, 3, 7, 14, 10, 15, 6, 8, 0, 5, 9, 2},
-			{6, 11, 13, 8, 1, 4, 10, 7, 9, 5, 0, 15, 14, 2, 3, 12},
-		},
-		{
-			{13, 2, 8, 4, 6, 15, 11, 1, 10, 9, 3, 14, 5, 0, 12, 7},
-			{1, 15, 13, 8, 10, 3, 7, 4, 12, 5, 6, 11, 0, 14, 9, 2},
-			{7, 11, 4, 1, 9, 12, 14, 2, 0, 6, 10, 13, 15, 3, 5, 8},
-			{2, 1, 14, 7, 4, 10, 8, 13, 15, 12, 9, 0, 3, 5, 6, 11},
-		},
-	}
-
-	result := "" // Инициализируем строку для хранения результата замены S-Box
-	for i := 0; i < 8; i++ {
-		// Получаем биты строки и столбца для текущего блока 6 бит
-		rowBit := string([]byte{block48[i*6], block48[i*6+5]})
-		lineBit := block48[i*6+1 : i*6+5]
+// processingDecodeInput преобразует шифротекст в шестнадцатеричной форме ("0x...0x...") в байты.
+// При нарушении формата возвращается *HexError или *BlockLengthError
+func (d *MyDES) processingDecodeInput(cipherText []byte) ([]byte, error) {
+	// Пробелы и переводы строк по краям (например, добавленные редактором) не являются частью шифротекста
+	input := string(bytes.TrimSpace(cipherText))
+	if input == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(input, "0x") {
+		return nil, &HexError{Block: 0, Value: input[:min(len(input), 16)], Reason: "шифротекст должен начинаться с 0x"}
+	}
+	inputList := strings.Split(input, "0x")[1:]
 
-		// Преобразуем строку и столбец в целочисленные значения
-		row, _ := strconv.ParseInt(rowBit, 2, 64)
-		line, _ := strconv.ParseInt(lineBit, 2, 64)
+	result := make([]byte, 0, len(inputList)*BlockSize)
+	for n, i := range inputList {
+		if len(i) > 2*BlockSize {
+			return nil, &BlockLengthError{Block: n, Length: (len(i) + 1) / 2}
+		}
+
+		if d.mode.IsStream() {
+			// В потоковых режимах блок записан побайтно, и неполным может быть только последний блок
+			decoded, err := hex.DecodeString(i)
+			if err != nil {
+				return nil, &HexError{Block: n, Value: i, Reason: err.Error()}
+			}
+			if len(decoded) != BlockSize && n != len(inputList)-1 {
+				return nil, &BlockLengthError{Block: n, Length: len(decoded)}
+			}
+			result = append(result, decoded...)
+			continue
+		}
 
-		// Получаем значение из таблицы S-Box и преобразуем его в бинарную строку
-		data := sBoxTable[i][row][line]
-		noFull := fmt.Sprintf("%04b", data)
-		result += noFull
+		decoded, err := strconv.ParseUint(i, 16, 64)
+		if err != nil {
+			return nil, &HexError{Block: n, Value: i, Reason: "ожидалось шестнадцатеричное число"}
+		}
+		result = binary.BigEndian.AppendUint64(result, decoded)
 	}
 
-	return result // Возвращаем результат замены S-Box
+	return result, nil
 }
 
-// sBoxCompression выполняет компрессию блока S-Box для 48-битного блока согласно таблице компрессии S-Box
-func (d *MyDES) sBoxCompression(num int, block48 string) string {
-	// Выполняем операцию NOT OR между 48-битным блоком и подключом
-	resultNotOr := d.notOr(block48, d.childKeys[num])
+// keyConversion преобразует исходный 64-битный ключ в 56-битный ключ и выполняет замену
+func (d *MyDES) keyConversion(key string) uint64 {
+	// Берем первые 64 бита ключа (с дополнением нулями) и переставляем их согласно таблице
+	return d.tables().pc1.apply(d.bitEncode(key))
+}
 
-	// Выполняем подстановку S-Box, используя полученный результат
-	return d.sBoxReplace(resultNotOr)
+// spinKey выполняет вращение для генерации подключей
+func (d *MyDES) spinKey(key string) [16]uint64 {
+	// Получение 56-битного ключа после замены
+	return d.spinHalves(d.keyConversion(key))
 }
 
-// pBoxReplacement заменяет 32-битный блок с использованием таблицы замены P-Box
-func (d *MyDES) pBoxReplacement(block32 string) string {
-	// Таблица замены P-Box для DES
-	pBoxReplaceTable := []int{
-		16, 7, 20, 21, 29, 12, 28, 17, 1, 15, 23, 26, 5, 18, 31, 10,
-		2, 8, 24, 14, 32, 27, 3, 9, 19, 13, 30, 6, 22, 11, 4, 25,
+// spinHalves вращает 28-битные половины 56-битного ключа после PC-1 и возвращает 16 ключей до PC-2
+func (d *MyDES) spinHalves(kc uint64) [16]uint64 {
+	first, second := uint32(kc>>28)&0xfffffff, uint32(kc)&0xfffffff
+
+	var subKeys [16]uint64
+
+	// Выполнение вращения 28-битных половин и создание 16 подключей
+	for i, shift := range d.tables().spin {
+		firstAfterSpin := (first<<shift | first>>(28-shift)) & 0xfffffff
+		secondAfterSpin := (second<<shift | second>>(28-shift)) & 0xfffffff
+		subKeys[i] = uint64(firstAfterSpin)<<28 | uint64(secondAfterSpin)
 	}
+	return subKeys
+}
 
-	// Выполняем замену P-Box
-	return d.replaceBlock(block32, pBoxReplaceTable)
+// keySelectionReplacement получает подключи в 48 бит путем выборочной перестановки
+func (d *MyDES) keySelectionReplacement(key string) keySchedule {
+	return d.scheduleFromKey(d.bitEncode(key))
 }
 
-// fFunction представляет собой функцию F, часть сети Фейстеля
-func (d *MyDES) fFunction(right string, isDecode bool, num int) string {
-	// Убедимся, что правая половина блока расширена до 48 бит
-	right = d.blockExtend(right)
+// scheduleFromKey вычисляет подключи 64-битного ключа. Перебор ключей вызывает ее напрямую,
+// без преобразования каждого кандидата в строку
+func (d *MyDES) scheduleFromKey(key uint64) keySchedule {
+	// Генерация подключей
+	subKeys := d.spinHalves(d.tables().pc1.apply(key))
 
-	var sbcResult string
-	if isDecode {
-		// Для расшифровки выполняем компрессию блока S-Box с конкретным подключом
-		sbcResult = d.sBoxCompression(15-num, right)
-	} else {
-		// Для шифрования выполняем компрессию блока S-Box с конкретным подключом
-		sbcResult = d.sBoxCompression(num, right)
+	// Выборочная перестановка каждого подключа
+	var schedule keySchedule
+	for i, childKey56 := range subKeys {
+		schedule[i] = d.tables().pc2.apply(childKey56)
 	}
-
-	// Выполняем замену P-Box для результата S-Box
-	return d.pBoxReplacement(sbcResult)
+	return schedule
 }
 
-// iteration выполняет одну итерацию сети Фейстеля
-func (d *MyDES) iteration(block string, key string, isDecode bool) string {
-	// Выбираем подключи на основе ключа
-	d.keySelectionReplacement(key)
-
-	// Итерируем 16 раз (количество раундов в DES)
-	for i := 0; i < 16; i++ {
-		// Разбиваем блок на левую и правую половины
-		left, right := block[0:32], block[32:64]
+// tables возвращает таблицы варианта DES, которыми пользуется экземпляр
+func (d *MyDES) tables() *Variant {
+	if d.variant == nil {
+		return standardVariant
+	}
+	return d.variant
+}
 
-		// Сохраняем левую половину для следующей итерации
-		nextLeft := right
+// initReplaceBlock выполняет начальную блочную перестановку
+func (d *MyDES) initReplaceBlock(block uint64) uint64 {
+	return d.tables().ip.apply(block)
+}
 
-		// Выполняем функцию F на правой половине
-		fResult := d.fFunction(right, isDecode, i)
+// endReplaceBlock выполняет конечную блочную перестановку
+func (d *MyDES) endReplaceBlock(block uint64) uint64 {
+	return d.tables().fp.apply(block)
+}
 
-		// Выполняем операцию NOT OR между левой половиной и результатом F
-		right = d.notOr(left, fResult)
+// blockExtend расширяет 32-битный блок до 48 бит с использованием таблицы расширения
+func (d *MyDES) blockExtend(block uint32) uint64 {
+	return d.tables().e.apply(uint64(block))
+}
 
-		// Обновляем блок для следующей итерации
-		block = nextLeft + right
+// sBoxReplace выполняет подстановку S-Box, преобразуя входные 48 бит в выходные 32 бита
+func (d *MyDES) sBoxReplace(block48 uint64) uint32 {
+	sBox := &d.tables().sBox
+	var result uint32
+	for i := 0; i < 8; i++ {
+		// Берем очередные 6 бит и получаем по ним 4-битное значение из S-Box
+		six := (block48 >> (42 - 6*i)) & 0x3f
+		result = result<<4 | uint32(sBox[i][six])
 	}
 
-	// Сцепляем правую и левую половины блока и возвращаем результат
-	//block = block[len(block)-32:] + block[:32]
-	return block[32:] + block[:32]
+	return result // Возвращаем результат замены S-Box
 }
 
-// encode выполняет шифрование DES в режиме CBC
-func (d *MyDES) Encode(input string, key string) string {
-	// Результирующая строка для закодированных блоков
-	result := ""
-
-	// Обрабатываем входную строку для получения блоков
-	blocks := d.processingEncodeInput(input)
-
-	// Используем IV как предыдущий блок для первой итерации
-	previousBlock := d.bitEncode(d.iv)
+// sBoxCompression выполняет компрессию блока S-Box для 48-битного блока согласно таблице компрессии S-Box
+func (d *MyDES) sBoxCompression(childKey, block48 uint64) uint32 {
+	// Выполняем операцию XOR между 48-битным блоком и подключом, затем подстановку S-Box
+	return d.sBoxReplace(block48 ^ childKey)
+}
 
-	// Итерируем по блокам
-	for _, block := range blocks {
-		// Выполняем операцию NOT OR с предыдущим блоком
-		block = d.notOr(block, previousBlock)
+// pBoxReplacement заменяет 32-битный блок с использованием таблицы замены P-Box
+func (d *MyDES) pBoxReplacement(block32 uint32) uint32 {
+	return uint32(d.tables().p.apply(uint64(block32)))
+}
 
-		// Заменяем блок перед итерацией
-		irbResult := d.initReplaceBlock(block)
+// fFunction представляет собой функцию F, часть сети Фейстеля
+func (d *MyDES) fFunction(right uint32, childKey uint64) uint32 {
+	// Расширяем правую половину блока до 48 бит
+	extended := d.blockExtend(right)
 
-		// Выполняем одну итерацию сети Фейстеля
-		blockResult := d.iteration(irbResult, key, false)
+	// Выполняем замену P-Box для результата S-Box
+	return d.pBoxReplacement(d.sBoxCompression(childKey, extended))
+}
 
-		// Заменяем блок после итерации
-		blockResult = d.endReplaceBlock(blockResult)
+// roundHook вызывается перед каждым раундом с его номером (с нуля), половинами блока и подключом раунда
+type roundHook func(round int, left, right uint32, childKey uint64)
+
+// roundCount возвращает число раундов сети Фейстеля. Недопустимое число вызывает панику с RoundsError,
+// чтобы блок не прошел через сеть без раундов, даже если вызывающий код не проверил его заранее
+func (d *MyDES) roundCount() int {
+	switch {
+	case d.rounds == 0:
+		return 16
+	case d.rounds < 0 || d.rounds > MaxRounds:
+		panic(RoundsError(d.rounds))
+	}
+	return d.rounds
+}
 
-		// Преобразуем результат в шестнадцатеричную форму и добавляем к общему результату
-		hexValue, err := binaryToHex(blockResult)
-		fmt.Println("16 форма - ", hexValue, err)
-		result += hexValue
-		// Обновляем предыдущий блок для следующей итерации
-		previousBlock = blockResult
+// iteration выполняет раунды сети Фейстеля (16 или заданное WithRounds число) над блоком с заранее
+// вычисленными подключами. Если hook не nil, он вызывается перед каждым раундом (см. TraceBlock)
+func (d *MyDES) iteration(block uint64, schedule *keySchedule, isDecode bool, hook roundHook) uint64 {
+	// Разбиваем блок на левую и правую половины
+	left, right := uint32(block>>32), uint32(block)
+
+	// После 16 раундов расписание повторяется: раунд i использует подключ i по модулю 16
+	rounds := d.roundCount()
+	for i := 0; i < rounds; i++ {
+		// Для расшифровки подключи используются в обратном порядке
+		childKey := schedule[i%16]
+		if isDecode {
+			childKey = schedule[(rounds-1-i)%16]
+		}
+		if hook != nil {
+			hook(i, left, right, childKey)
+		}
+		left, right = right, left^d.fFunction(right, childKey)
 	}
 
-	return result
+	// Сцепляем правую и левую половины блока и возвращаем результат
+	return uint64(right)<<32 | uint64(left)
 }
 
-// decode выполняет расшифровку DES в режиме CBC
-func (d *MyDES) Decode(cipherText []byte, key string) string {
-	// Результирующий массив для расшифрованных блоков
-	var result []string
+// Encode выполняет шифрование DES (или Triple DES) в выбранном режиме (по умолчанию CBC).
+// Недопустимое число раундов возвращается как RoundsError
+func (d *MyDES) Encode(input string, key string) (string, error) {
+	if err := d.checkRounds(); err != nil {
+		return "", err
+	}
 
-	// Обрабатываем входные данные для получения блоков
-	blocks := d.processingDecodeInput(cipherText)
+	// Блочный шифр для выбранного алгоритма
+	block := d.newBlock(key)
 
-	// Используем IV как предыдущий блок для первой итерации
-	previousBlock := d.bitEncode(d.iv)
+	// Обрабатываем входную строку, шифруем ее и переводим результат в шестнадцатеричную форму
+	result := d.cryptBlocks(block, d.bitEncode(d.iv), d.processingEncodeInput(input), false)
+	return d.processingEncodeOutput(result), nil
+}
 
-	// Итерируем по блокам
-	for _, block := range blocks {
-		// Заменяем блок перед итерацией
-		irbResult := d.initReplaceBlock(block)
+// Decode выполняет расшифровку DES (или Triple DES) в выбранном режиме (по умолчанию CBC).
+// Ошибки формата шифротекста возвращаются как *HexError или *BlockLengthError,
+// ошибки дополнения в режимах ECB и CBC - как *PaddingError, недопустимое число раундов - как RoundsError
+func (d *MyDES) Decode(cipherText []byte, key string) (string, error) {
+	if err := d.checkRounds(); err != nil {
+		return "", err
+	}
 
-		// Выполняем одну итерацию сети Фейстеля для расшифровки
-		blockResult := d.iteration(irbResult, key, true)
+	// Блочный шифр для выбранного алгоритма
+	block := d.newBlock(key)
 
-		// Заменяем блок после итерации
-		blockResult = d.endReplaceBlock(blockResult)
+	// Обрабатываем входные данные
+	data, err := d.processingDecodeInput(cipherText)
+	if err != nil {
+		return "", err
+	}
 
-		// Выполняем операцию NOT OR с предыдущим блоком
-		blockResult = d.notOr(blockResult, previousBlock)
+	// Расшифровываем данные
+	result := d.cryptBlocks(block, d.bitEncode(d.iv), data, true)
 
-		// Разбиваем результат на 8-битные части и добавляем к общему результату
-		for i := 0; i < len(blockResult); i += 8 {
-			result = append(result, blockResult[i:i+8])
+	// Удаляем дополнение в блочных режимах
+	if !d.mode.IsStream() {
+		if result, err = d.padding.unpad(result); err != nil {
+			return "", err
 		}
-
-		// Обновляем предыдущий блок для следующей итерации
-		previousBlock = block
 	}
 
-	// Преобразуем конечный результат в строку
-	return d.bitDecode(result)
+	// Преобразуем результат в строку
+	return d.bitDecode(result), nil
 }
 
-func binaryToHex(binaryStr string) (string, error) {
-	decimalValue := new(big.Int)
-	decimalValue, success := decimalValue.SetString(binaryStr, 2)
-	if !success {
-		return "", fmt.Errorf("невозможно преобразовать в десятичное число")
-	}
-
-	hexValue := fmt.Sprintf("0x%X", decimalValue)
-	return hexValue, nil
+// binaryToHex преобразует 64-битный блок в шестнадцатеричную строку с префиксом 0x
+func binaryToHex(block uint64) string {
+	return fmt.Sprintf("0x%X", block)
 }
@@ -0,0 +1,91 @@
+package myDes
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// randomInput возвращает детерминированную псевдослучайную строку заданной длины
+func randomInput(seed int64, n int) string {
+	buf := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(buf)
+	return string(buf)
+}
+
+// TestEncodeMatchesLegacy проверяет, что реализация на uint64 с нулевым дополнением дает те же шифротексты,
+// что и строковая, и что такие шифротексты расшифровываются обратно
+func TestEncodeMatchesLegacy(t *testing.T) {
+	inputs := []string{"", "a", "Hello, DES!", "12345678", "Привет, мир! Шифруем русский текст"}
+	for i := 0; i < 16; i++ {
+		inputs = append(inputs, randomInput(int64(i), i*7+1))
+	}
+	keys := []string{"Super_Secret_key", "key", "", "12345678"}
+
+	for _, key := range keys {
+		for _, input := range inputs {
+			d := NewMyDES("01234567", WithPadding(PaddingZero))
+			want := newLegacyDES("01234567").Encode(input, key)
+			got := mustEncode(t, d, input, key)
+			if got != want {
+				t.Fatalf("Encode(%q, %q) = %q, ожидалось %q", input, key, got, want)
+			}
+
+			plain, err := d.Decode([]byte(want), key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain != strings.TrimRight(input, "\x00") {
+				t.Fatalf("Decode(%q, %q) = %q, ожидалось %q", want, key, plain, input)
+			}
+		}
+	}
+}
+
+// mustEncode шифрует input через Encode и завершает тест при ошибке
+func mustEncode(tb testing.TB, d *MyDES, input, key string) string {
+	tb.Helper()
+	encoded, err := d.Encode(input, key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return encoded
+}
+
+func BenchmarkLegacyEncode(b *testing.B) {
+	input := randomInput(1, 4096)
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		newLegacyDES("01234567").Encode(input, "Super_Secret_key")
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	input := randomInput(1, 4096)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewMyDES("01234567").Encode(input, "Super_Secret_key"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLegacyDecode(b *testing.B) {
+	input := randomInput(1, 4096)
+	cipherText := []byte(newLegacyDES("01234567").Encode(input, "Super_Secret_key"))
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		newLegacyDES("01234567").Decode(cipherText, "Super_Secret_key")
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	input := randomInput(1, 4096)
+	cipherText := []byte(mustEncode(b, NewMyDES("01234567"), input, "Super_Secret_key"))
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewMyDES("01234567").Decode(cipherText, "Super_Secret_key")
+	}
+}
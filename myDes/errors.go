@@ -0,0 +1,24 @@
+package myDes
+
+import "strconv"
+
+// HexError описывает шифротекст, который не удалось разобрать как последовательность блоков "0x..."
+type HexError struct {
+	Block  int    // Номер блока, начиная с нуля
+	Value  string // Фрагмент, который не удалось разобрать
+	Reason string // Причина ошибки
+}
+
+func (e *HexError) Error() string {
+	return "myDes: некорректная шестнадцатеричная запись блока " + strconv.Itoa(e.Block) + " (" + strconv.Quote(e.Value) + "): " + e.Reason
+}
+
+// BlockLengthError описывает блок шифротекста неверной длины
+type BlockLengthError struct {
+	Block  int // Номер блока, начиная с нуля
+	Length int // Длина блока в байтах
+}
+
+func (e *BlockLengthError) Error() string {
+	return "myDes: блок " + strconv.Itoa(e.Block) + " имеет длину " + strconv.Itoa(e.Length) + " байт, ожидалось не более " + strconv.Itoa(BlockSize)
+}
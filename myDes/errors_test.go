@@ -0,0 +1,49 @@
+package myDes
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestDecodeErrors проверяет, что некорректный шифротекст приводит к ошибке нужного типа, а не к панике
+func TestDecodeErrors(t *testing.T) {
+	var (
+		hexErr     *HexError
+		lengthErr  *BlockLengthError
+		paddingErr *PaddingError
+	)
+	tests := []struct {
+		name  string
+		mode  Mode
+		input string
+		want  any
+	}{
+		{"обычный текст", ModeCBC, "просто текстовый файл", &hexErr},
+		{"не шестнадцатеричные цифры", ModeCBC, "0x12AB0xZZZZ", &hexErr},
+		{"пустой блок", ModeCBC, "0x12AB0x0x34", &hexErr},
+		{"слишком длинный блок", ModeCBC, "0x0123456789ABCDEF01", &lengthErr},
+		{"нечетное число цифр", ModeCTR, "0x123", &hexErr},
+		{"неполный блок в середине", ModeCTR, "0x12340x0123456789ABCDEF", &lengthErr},
+		{"пустой шифротекст", ModeCBC, "", &paddingErr},
+		{"неверное дополнение", ModeCBC, "0x1", &paddingErr},
+	}
+
+	for _, tt := range tests {
+		_, err := NewMyDES("01234567", WithMode(tt.mode)).Decode([]byte(tt.input), "key")
+		if !errors.As(err, tt.want) {
+			t.Errorf("%s: Decode(%q) = %v, ожидалась ошибка типа %T", tt.name, tt.input, err, tt.want)
+		}
+	}
+}
+
+// TestDecodeTrimsSpace проверяет, что перевод строки в конце файла не мешает расшифровке
+func TestDecodeTrimsSpace(t *testing.T) {
+	d := NewMyDES("01234567")
+	got, err := d.Decode([]byte(mustEncode(t, d, "text", "key")+"\r\n"), "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "text" {
+		t.Fatalf("Decode = %q", got)
+	}
+}
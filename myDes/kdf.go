@@ -0,0 +1,152 @@
+package myDes
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF определяет функцию выработки ключа из пароля
+type KDF byte
+
+const (
+	KDFNone   KDF = iota // Ключ задан напрямую
+	KDFPBKDF2            // PBKDF2-HMAC-SHA256, Params[0] - число итераций
+	KDFScrypt            // scrypt, Params - N, r и p
+)
+
+// String возвращает название функции выработки ключа
+func (k KDF) String() string {
+	switch k {
+	case KDFNone:
+		return "none"
+	case KDFPBKDF2:
+		return "PBKDF2-HMAC-SHA256"
+	case KDFScrypt:
+		return "scrypt"
+	default:
+		return "unknown"
+	}
+}
+
+// Параметры выработки ключа по умолчанию и допустимые пределы.
+// Пределы защищают от контейнеров, заголовок которых требует непомерных вычислений или памяти
+const (
+	DefaultPBKDF2Iterations = 100000
+	DefaultScryptN          = 1 << 15
+	DefaultScryptR          = 8
+	DefaultScryptP          = 1
+
+	SaltSize            = 16       // Длина соли, вырабатываемой при шифровании
+	minSaltSize         = 8        // Минимальная длина соли в заголовке
+	maxPBKDF2Iterations = 10000000 // Максимальное число итераций PBKDF2
+	maxScryptMemory     = 1 << 28  // Максимальный объем памяти scrypt (128 * N * r байт)
+)
+
+var (
+	// ErrEmptyPassphrase возвращается при попытке выработать ключ из пустого пароля
+	ErrEmptyPassphrase = errors.New("myDes: пароль не может быть пустым")
+	// ErrPassphraseNUL возвращается, если пароль содержит нулевой байт
+	ErrPassphraseNUL = errors.New("myDes: пароль не может содержать нулевой байт")
+)
+
+// KDFParams - параметры выработки ключа, сохраняемые в заголовке контейнера
+type KDFParams struct {
+	KDF    KDF       // Функция выработки ключа
+	Salt   []byte    // Соль
+	Params [3]uint32 // Параметры функции (число итераций, стоимость и т.п.)
+}
+
+// PBKDF2 возвращает параметры PBKDF2-HMAC-SHA256 с заданным числом итераций
+func PBKDF2(iterations uint32) KDFParams {
+	return KDFParams{KDF: KDFPBKDF2, Params: [3]uint32{iterations}}
+}
+
+// Scrypt возвращает параметры scrypt со стоимостью n, размером блока r и параллелизмом p
+func Scrypt(n, r, p uint32) KDFParams {
+	return KDFParams{KDF: KDFScrypt, Params: [3]uint32{n, r, p}}
+}
+
+// withSalt возвращает копию параметров с новой случайной солью
+func (p KDFParams) withSalt() (KDFParams, error) {
+	p.Salt = make([]byte, SaltSize)
+	if _, err := rand.Read(p.Salt); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// validate проверяет, что функция известна, а ее параметры лежат в допустимых пределах
+func (p KDFParams) validate() error {
+	invalid := func(reason string) error {
+		return &ContainerError{Reason: "параметры " + p.KDF.String() + ": " + reason}
+	}
+
+	switch p.KDF {
+	case KDFNone:
+		return nil
+	case KDFPBKDF2:
+		if iterations := p.Params[0]; iterations == 0 || iterations > maxPBKDF2Iterations {
+			return invalid("недопустимое число итераций " + strconv.FormatUint(uint64(iterations), 10))
+		}
+	case KDFScrypt:
+		n, r, par := uint64(p.Params[0]), uint64(p.Params[1]), uint64(p.Params[2])
+		if n < 2 || n&(n-1) != 0 {
+			return invalid("N должно быть степенью двойки")
+		}
+		if r == 0 || par == 0 || r*par >= 1<<30 || 128*n*r > maxScryptMemory {
+			return invalid("r и p вне допустимых пределов")
+		}
+	default:
+		return &ContainerError{Reason: "неизвестная функция выработки ключа " + strconv.Itoa(int(p.KDF))}
+	}
+
+	if len(p.Salt) < minSaltSize {
+		return invalid("соль короче " + strconv.Itoa(minSaltSize) + " байт")
+	}
+	return nil
+}
+
+// DeriveKey вырабатывает из пароля ключ длиной size байт стандартными PBKDF2 или scrypt.
+// HMAC дополняет ключ нулями, поэтому пароли "key" и "key\x00" дали бы один и тот же ключ;
+// пароли с нулевым байтом отклоняются
+func (p KDFParams) DeriveKey(passphrase string, size int) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if p.KDF == KDFNone {
+		return []byte(passphrase), nil
+	}
+	if strings.IndexByte(passphrase, 0) >= 0 {
+		return nil, ErrPassphraseNUL
+	}
+	return p.derive(passphrase, size)
+}
+
+// derive вырабатывает ключ без проверки параметров
+func (p KDFParams) derive(passphrase string, size int) ([]byte, error) {
+	switch p.KDF {
+	case KDFPBKDF2:
+		return pbkdf2.Key([]byte(passphrase), p.Salt, int(p.Params[0]), size, sha256.New), nil
+	case KDFScrypt:
+		return scrypt.Key([]byte(passphrase), p.Salt, int(p.Params[0]), int(p.Params[1]), int(p.Params[2]), size)
+	default:
+		return []byte(passphrase), nil
+	}
+}
+
+// keySize возвращает длину ключа, вырабатываемого из пароля для алгоритма
+func (a Algorithm) keySize() int {
+	if a == AlgorithmTripleDES {
+		return 3 * BlockSize
+	}
+	return BlockSize
+}
@@ -0,0 +1,125 @@
+package myDes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// TestDeriveKnownVectors проверяет PBKDF2-HMAC-SHA256 и scrypt по векторам из RFC 7914.
+// Соль из RFC короче допустимой в контейнере, поэтому выработка вызывается без проверки параметров
+func TestDeriveKnownVectors(t *testing.T) {
+	tests := []struct {
+		params     KDFParams
+		passphrase string
+		want       string
+	}{
+		{KDFParams{KDF: KDFPBKDF2, Salt: []byte("salt"), Params: [3]uint32{1}}, "passwd", "55ac046e56e3089f"},
+		{KDFParams{KDF: KDFScrypt, Salt: []byte("NaCl"), Params: [3]uint32{1024, 8, 16}}, "password", "fdbabe1c9d347200"},
+	}
+	for _, tt := range tests {
+		got, err := tt.params.derive(tt.passphrase, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("%s: получено %x, ожидалось %s", tt.params.KDF, got, tt.want)
+		}
+	}
+}
+
+// TestSealOpenPassphrase проверяет шифрование паролем: параметры и соль берутся из заголовка
+func TestSealOpenPassphrase(t *testing.T) {
+	plain := []byte("файл, зашифрованный на пароле")
+	for _, params := range []KDFParams{PBKDF2(1000), Scrypt(1024, 8, 1)} {
+		for _, algorithm := range []Algorithm{AlgorithmDES, AlgorithmTripleDES} {
+			d := NewMyDES("", WithAlgorithm(algorithm), WithKDF(params))
+			sealed, err := d.Seal(plain, "длинный пароль, который не обрезается до 8 байт")
+			if err != nil {
+				t.Fatalf("%s/%s: %v", params.KDF, algorithm, err)
+			}
+
+			got, err := NewMyDES("").Open(sealed, "длинный пароль, который не обрезается до 8 байт")
+			if err != nil {
+				t.Fatalf("%s/%s: %v", params.KDF, algorithm, err)
+			}
+			if !bytes.Equal(got, plain) {
+				t.Fatalf("%s/%s: получено %q", params.KDF, algorithm, got)
+			}
+
+			// Пароль с тем же началом дает другой ключ
+			if got, err := NewMyDES("").Open(sealed, "длинный пароль"); err == nil && bytes.Equal(got, plain) {
+				t.Fatalf("%s/%s: расшифровано паролем, совпадающим только в начале", params.KDF, algorithm)
+			}
+		}
+	}
+}
+
+// TestDeriveKeyStandard проверяет, что DeriveKey совпадает со стандартными PBKDF2-HMAC-SHA256 и scrypt:
+// ключ, выработанный из пароля, можно получить любой другой реализацией
+func TestDeriveKeyStandard(t *testing.T) {
+	tests := []struct {
+		params     KDFParams
+		passphrase string
+		want       string
+	}{
+		{KDFParams{KDF: KDFPBKDF2, Salt: []byte("saltSALTsaltSALTsaltSALTsaltSALTsalt"), Params: [3]uint32{4096}},
+			"passwordPASSWORDpassword", "348c89dbcbd32b2f"},
+		{KDFParams{KDF: KDFScrypt, Salt: []byte("SodiumChloride"), Params: [3]uint32{16384, 8, 1}},
+			"pleaseletmein", "7023bdcb3afd7348"},
+	}
+	for _, tt := range tests {
+		got, err := tt.params.DeriveKey(tt.passphrase, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("%s: получено %x, ожидалось %s", tt.params.KDF, got, tt.want)
+		}
+	}
+}
+
+// TestDeriveKeyDistinguishesPassphrases проверяет, что пароль с нулевым байтом отклоняется, а не совпадает
+// с паролем без него, а одинаковые пароли с разной солью дают разные ключи
+func TestDeriveKeyDistinguishesPassphrases(t *testing.T) {
+	params, err := PBKDF2(1000).withSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := params.DeriveKey("key\x00", 8); !errors.Is(err, ErrPassphraseNUL) {
+		t.Fatalf(`"key\x00": %v, ожидалась ErrPassphraseNUL`, err)
+	}
+
+	first, _ := params.DeriveKey("key", 8)
+	other, err := PBKDF2(1000).withSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := other.DeriveKey("key", 8); bytes.Equal(first, second) {
+		t.Fatal("разная соль дает одинаковый ключ")
+	}
+}
+
+// TestKDFParamsValidation проверяет отказ от пустого пароля и параметров вне допустимых пределов
+func TestKDFParamsValidation(t *testing.T) {
+	salt := make([]byte, SaltSize)
+	tests := []KDFParams{
+		{KDF: KDFPBKDF2, Salt: salt},
+		{KDF: KDFPBKDF2, Salt: salt, Params: [3]uint32{maxPBKDF2Iterations + 1}},
+		{KDF: KDFPBKDF2, Salt: salt[:4], Params: [3]uint32{1000}},
+		{KDF: KDFScrypt, Salt: salt, Params: [3]uint32{1000, 8, 1}},
+		{KDF: KDFScrypt, Salt: salt, Params: [3]uint32{1 << 30, 8, 1}},
+		{KDF: 9, Salt: salt},
+	}
+	for _, params := range tests {
+		var containerErr *ContainerError
+		if _, err := params.DeriveKey("пароль", 8); !errors.As(err, &containerErr) {
+			t.Errorf("%+v: %v, ожидалась *ContainerError", params, err)
+		}
+	}
+
+	if _, err := PBKDF2(1000).DeriveKey("", 8); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("пустой пароль: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package myDes
+
+// KeyRound - состояние расписания ключей после одного раунда
+type KeyRound struct {
+	Round  int    // Номер раунда (1-16)
+	Shift  int    // Циклический сдвиг половин в этом раунде (1 или 2 бита)
+	C      uint32 // Левая 28-битная половина после сдвига
+	D      uint32 // Правая 28-битная половина после сдвига
+	SubKey uint64 // Подключ раунда после PC-2 (48 бит)
+}
+
+// KeyScheduleTrace - все промежуточные значения расписания ключей DES
+type KeyScheduleTrace struct {
+	Key    uint64       // Исходный 64-битный ключ
+	PC1    uint64       // Результат перестановки PC-1 (56 бит, биты четности отброшены)
+	C0     uint32       // Левая половина PC-1
+	D0     uint32       // Правая половина PC-1
+	Rounds [16]KeyRound // Состояние после каждого раунда
+}
+
+// TraceKeySchedule вычисляет расписание 8-байтового ключа DES и записывает результат PC-1,
+// половины C и D после каждого сдвига и 16 подключей после PC-2. Для ключа Triple DES
+// расписание строится отдельно для каждой 8-байтовой части
+func TraceKeySchedule(key []byte) (*KeyScheduleTrace, error) {
+	return (*Variant)(nil).TraceKeySchedule(key)
+}
+
+// TraceKeySchedule вычисляет расписание ключа по таблицам PC-1, PC-2 и сдвигам варианта (nil - стандарт)
+func (v *Variant) TraceKeySchedule(key []byte) (*KeyScheduleTrace, error) {
+	if len(key) != BlockSize {
+		return nil, KeySizeError(len(key))
+	}
+
+	d := &MyDES{variant: v}
+	t := &KeyScheduleTrace{
+		Key: d.bitEncode(string(key)),
+		PC1: d.keyConversion(string(key)),
+	}
+	t.C0, t.D0 = uint32(t.PC1>>28)&0xfffffff, uint32(t.PC1)&0xfffffff
+
+	// Те же половины, что и в spinKey
+	subKeys := d.spinKey(string(key))
+	schedule := d.keySelectionReplacement(string(key))
+	for i, shift := range d.tables().shifts {
+		t.Rounds[i] = KeyRound{
+			Round:  i + 1,
+			Shift:  shift,
+			C:      uint32(subKeys[i]>>28) & 0xfffffff,
+			D:      uint32(subKeys[i]) & 0xfffffff,
+			SubKey: schedule[i],
+		}
+	}
+	return t, nil
+}
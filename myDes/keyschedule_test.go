@@ -0,0 +1,47 @@
+package myDes
+
+import "testing"
+
+// TestTraceKeySchedule сверяет расписание с разобранным вручную примером для ключа 133457799BBCDFF1
+func TestTraceKeySchedule(t *testing.T) {
+	trace, err := TraceKeySchedule(uint64Bytes(0x133457799BBCDFF1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trace.PC1 != 0xF0CCAAF556678F {
+		t.Errorf("PC1 = %014X", trace.PC1)
+	}
+	if trace.C0 != 0xF0CCAAF || trace.D0 != 0x556678F {
+		t.Errorf("C0, D0 = %07X, %07X", trace.C0, trace.D0)
+	}
+
+	first, last := trace.Rounds[0], trace.Rounds[15]
+	if first.C != 0xE19955F || first.D != 0xAACCF1E || first.Shift != 1 {
+		t.Errorf("раунд 1: C = %07X, D = %07X, сдвиг %d", first.C, first.D, first.Shift)
+	}
+	if first.SubKey != 0x1B02EFFC7072 || last.SubKey != 0xCB3D8B0E17F5 {
+		t.Errorf("K1 = %012X, K16 = %012X", first.SubKey, last.SubKey)
+	}
+
+	// После всех сдвигов (28 бит) половины возвращаются к исходным
+	if last.C != trace.C0 || last.D != trace.D0 {
+		t.Error("C16, D16 не совпадают с C0, D0")
+	}
+
+	shifts := 0
+	schedule := (&MyDES{}).keySelectionReplacement(string(uint64Bytes(0x133457799BBCDFF1)))
+	for i, r := range trace.Rounds {
+		shifts += r.Shift
+		if r.SubKey != schedule[i] {
+			t.Errorf("раунд %d: подключ не совпадает с расписанием шифра", i+1)
+		}
+	}
+	if shifts != 28 {
+		t.Errorf("сумма сдвигов %d", shifts)
+	}
+
+	if _, err := TraceKeySchedule([]byte("Super_Secret_key")); err == nil {
+		t.Error("принят ключ из 16 байт")
+	}
+}
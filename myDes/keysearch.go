@@ -0,0 +1,225 @@
+package myDes
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrKeyNotFound возвращается, если ни один ключ пространства перебора не подходит к известной паре
+	ErrKeyNotFound = errors.New("myDes: ключ не найден в пространстве перебора")
+	// ErrSearchMask возвращается, если маска не содержит неизвестных бит ключа
+	ErrSearchMask = errors.New("myDes: маска не содержит неизвестных бит ключа (биты четности не считаются)")
+	// ErrSearchPair возвращается, если блоки известной пары или ключ и маска не по 8 байт
+	ErrSearchPair = errors.New("myDes: открытый текст, шифротексты, ключ и маска перебора должны быть по 8 байт")
+)
+
+const (
+	// parityBits - младшие биты байтов ключа: PC-1 их отбрасывает, поэтому они не перебираются
+	parityBits = 0x0101010101010101
+	// searchChunk - число кандидатов, которые горутина берет за раз; между порциями проверяется отмена
+	searchChunk = 1 << 14
+	// defaultProgressInterval - период вызова KeySearch.Progress по умолчанию
+	defaultProgressInterval = time.Second
+)
+
+// KeySearch описывает перебор ключа одинарного DES по известной паре открытый текст - шифротекст
+type KeySearch struct {
+	Plaintext  []byte // Известный открытый текст (8 байт)
+	Ciphertext []byte // Его шифротекст на искомом ключе
+	// ComplementCiphertext - шифротекст инвертированного открытого текста на том же ключе (необязателен).
+	// По свойству дополнения DES E(^K, ^P) = ^E(K, P), поэтому одно шифрование P на кандидате K
+	// проверяет сразу K (сравнением с Ciphertext) и ^K (сравнением с ^ComplementCiphertext).
+	// Используется только при маске на все 56 бит ключа (см. SearchKey)
+	ComplementCiphertext []byte
+	Key                  []byte // Известные биты ключа; биты под маской не важны
+	Mask                 []byte // Неизвестные биты ключа; биты четности из маски отбрасываются
+
+	Progress         func(SearchProgress) // Вызывается периодически из отдельной горутины и один раз в конце
+	ProgressInterval time.Duration        // Период вызова Progress (0 - раз в секунду)
+}
+
+// SearchProgress - состояние перебора
+type SearchProgress struct {
+	Tested  uint64 // Проверено кандидатов (по одному шифрованию на кандидата)
+	Total   uint64 // Всего кандидатов
+	Elapsed time.Duration
+}
+
+// Rate возвращает скорость перебора в кандидатах в секунду
+func (p SearchProgress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Tested) / p.Elapsed.Seconds()
+}
+
+// Remaining оценивает время до конца перебора всех кандидатов при текущей скорости
+func (p SearchProgress) Remaining() time.Duration {
+	rate := p.Rate()
+	if rate == 0 {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Tested) / rate * float64(time.Second))
+}
+
+// SearchResult - результат перебора
+type SearchResult struct {
+	Key        []byte // Найденный ключ с исправленными битами четности (nil, если не найден)
+	Complement bool   // Ключ найден по свойству дополнения: он равен инвертированному кандидату (только при маске на все 56 бит)
+	Space      uint64 // Число ключей (без учета бит четности), которые покрывает перебор
+	Total      uint64 // Число кандидатов, то есть шифрований в худшем случае
+	Tested     uint64 // Число проверенных кандидатов
+	Elapsed    time.Duration
+}
+
+// SearchKey перебирает неизвестные биты ключа DES в нескольких горутинах (см. WithWorkers),
+// используя вариант таблиц и число раундов экземпляра. Перебор прекращается, когда ключ найден
+// или ctx отменен; в последнем случае возвращается частичный результат и ошибка ctx.
+//
+// Если задан ComplementCiphertext, одним шифрованием проверяются два ключа, K и ^K. Число кандидатов
+// сокращается вдвое, только когда маска покрывает все 56 бит ключа (без бит четности): пространство
+// замкнуто относительно дополнения, и перебираются кандидаты с нулевым старшим неизвестным битом.
+// При неполной маске ^K имеет инвертированные известные биты и противоречит Key, поэтому пара для
+// дополнения не используется и перебор не сокращается
+func (d *MyDES) SearchKey(ctx context.Context, s KeySearch) (*SearchResult, error) {
+	if err := d.checkRounds(); err != nil {
+		return nil, err
+	}
+	complement := len(s.ComplementCiphertext) > 0
+	if len(s.Plaintext) != BlockSize || len(s.Ciphertext) != BlockSize || len(s.Key) != BlockSize ||
+		len(s.Mask) != BlockSize || complement && len(s.ComplementCiphertext) != BlockSize {
+		return nil, ErrSearchPair
+	}
+	mask := binary.BigEndian.Uint64(s.Mask) &^ parityBits
+	if mask == 0 {
+		return nil, ErrSearchMask
+	}
+
+	// Начальная и конечная перестановки от ключа не зависят: открытый текст переставляется один раз,
+	// а результат раундов сравнивается с шифротекстом после начальной перестановки
+	probe := &MyDES{variant: d.variant, rounds: d.rounds}
+	start := probe.initReplaceBlock(binary.BigEndian.Uint64(s.Plaintext))
+	target := probe.initReplaceBlock(binary.BigEndian.Uint64(s.Ciphertext))
+	var complementTarget uint64
+	if complement {
+		complementTarget = probe.initReplaceBlock(^binary.BigEndian.Uint64(s.ComplementCiphertext))
+	}
+
+	// Неизвестные биты от старшего к младшему; номер кандидата раскладывается по ним
+	known := binary.BigEndian.Uint64(s.Key) &^ mask
+	var positions []uint64
+	for bit := 63; bit >= 0; bit-- {
+		if mask>>bit&1 != 0 {
+			positions = append(positions, 1<<bit)
+		}
+	}
+	result := &SearchResult{Space: 1 << len(positions)}
+	if complement && mask == ^uint64(parityBits) {
+		// Старший бит кандидатов всегда нулевой, ключи с единицей проверяются как ^K
+		positions = positions[1:]
+	} else {
+		complement = false
+	}
+	result.Total = 1 << len(positions)
+
+	workers := d.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = int(min(uint64(workers), (result.Total+searchChunk-1)/searchChunk))
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		next, tested atomic.Uint64
+		found        sync.Once
+		wg           sync.WaitGroup
+	)
+	begin := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for searchCtx.Err() == nil {
+				first := next.Add(searchChunk) - searchChunk
+				if first >= result.Total {
+					return
+				}
+				last := min(first+searchChunk, result.Total)
+				for i := first; i < last; i++ {
+					candidate := known
+					for j, bit := range positions {
+						if i>>(len(positions)-1-j)&1 != 0 {
+							candidate |= bit
+						}
+					}
+					schedule := probe.scheduleFromKey(candidate)
+					out := probe.iteration(start, &schedule, false, nil)
+					if out != target && (!complement || out != complementTarget) {
+						continue
+					}
+					found.Do(func() {
+						result.Complement = out != target
+						if result.Complement {
+							candidate = ^candidate
+						}
+						result.Key = FixParity(binary.BigEndian.AppendUint64(nil, candidate))
+						cancel()
+					})
+					last = i + 1
+					break
+				}
+				tested.Add(last - first)
+			}
+		}()
+	}
+
+	progress := func() SearchProgress {
+		return SearchProgress{Tested: tested.Load(), Total: result.Total, Elapsed: time.Since(begin)}
+	}
+	done := make(chan struct{})
+	var reporter sync.WaitGroup
+	if s.Progress != nil {
+		interval := s.ProgressInterval
+		if interval <= 0 {
+			interval = defaultProgressInterval
+		}
+		reporter.Add(1)
+		go func() {
+			defer reporter.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					s.Progress(progress())
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	reporter.Wait()
+	result.Tested, result.Elapsed = tested.Load(), time.Since(begin)
+	if s.Progress != nil {
+		s.Progress(progress())
+	}
+
+	switch {
+	case result.Key != nil:
+		return result, nil
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	default:
+		return result, ErrKeyNotFound
+	}
+}
@@ -0,0 +1,173 @@
+package myDes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// searchMask возвращает маску из bits случайных бит ключа, не считая бит четности
+func searchMask(rnd *rand.Rand, bits int) []byte {
+	mask := make([]byte, BlockSize)
+	for _, i := range rnd.Perm(56)[:bits] {
+		mask[i/7] |= 0x80 >> (i % 7)
+	}
+	return mask
+}
+
+// knownPair шифрует открытый текст и его дополнение на ключе key
+func knownPair(d *MyDES, key, plain []byte) (cipher, complement []byte) {
+	b := d.newBlock(string(key))
+	inverted := make([]byte, BlockSize)
+	for i := range plain {
+		inverted[i] = ^plain[i]
+	}
+	cipher, complement = make([]byte, BlockSize), make([]byte, BlockSize)
+	b.Encrypt(cipher, plain)
+	b.Encrypt(complement, inverted)
+	return cipher, complement
+}
+
+// TestSearchKey находит ключ по 16 неизвестным битам обычным перебором, с парой для дополнения
+// и в DES с уменьшенным числом раундов
+func TestSearchKey(t *testing.T) {
+	key := FixParity([]byte("Brute_8K"))
+	plain := []byte("KnownTxt")
+
+	tests := []struct {
+		name       string
+		d          *MyDES
+		complement bool // Передавать шифротекст дополнения
+	}{
+		{"plain", NewMyDES("01234567", WithWorkers(4)), false},
+		// При неполной маске пара для дополнения не сокращает и не расширяет перебор
+		{"complement pair, partial mask", NewMyDES("01234567", WithWorkers(4)), true},
+		{"4 rounds", NewMyDES("01234567", WithRounds(4)), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cipher, complement := knownPair(tc.d, key, plain)
+			s := KeySearch{Plaintext: plain, Ciphertext: cipher, Key: key, Mask: searchMask(rand.New(rand.NewSource(1)), 16)}
+			if tc.complement {
+				s.ComplementCiphertext = complement
+			}
+			result, err := tc.d.SearchKey(context.Background(), s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(result.Key, key) {
+				t.Errorf("найден ключ %X, ожидался %X", result.Key, key)
+			}
+			if result.Complement {
+				t.Error("ключ найден по свойству дополнения при неполной маске")
+			}
+			if result.Total != 1<<16 || result.Space != 1<<16 || result.Tested == 0 || result.Tested > result.Total {
+				t.Errorf("проверено %d из %d, пространство %d", result.Tested, result.Total, result.Space)
+			}
+		})
+	}
+}
+
+// TestSearchKeyKnownBits проверяет, что при неполной маске не возвращается ключ,
+// противоречащий известным битам
+func TestSearchKeyKnownBits(t *testing.T) {
+	key := FixParity([]byte("Brute_8K"))
+	plain := []byte("KnownTxt")
+	inverted := make([]byte, BlockSize)
+	for i := range key {
+		inverted[i] = ^key[i]
+	}
+	d := NewMyDES("01234567", WithWorkers(2))
+	cipher, complement := knownPair(d, key, plain)
+	result, err := d.SearchKey(context.Background(), KeySearch{
+		Plaintext:            plain,
+		Ciphertext:           cipher,
+		ComplementCiphertext: complement,
+		Key:                  inverted,
+		Mask:                 searchMask(rand.New(rand.NewSource(1)), 12),
+	})
+	if !errors.Is(err, ErrKeyNotFound) || result.Key != nil || result.Space != 1<<12 {
+		t.Errorf("ошибка %v, ключ %X, пространство %d", err, result.Key, result.Space)
+	}
+}
+
+// TestSearchKeyNotFound перебирает все кандидаты, не подходящие к паре, и вызывает Progress в конце
+func TestSearchKeyNotFound(t *testing.T) {
+	key := FixParity([]byte("Brute_8K"))
+	var last SearchProgress
+	// Маска с битами четности: они не перебираются
+	mask := []byte{0, 0, 0, 0, 0, 0, 0xff, 0xff}
+	result, err := NewMyDES("01234567").SearchKey(context.Background(), KeySearch{
+		Plaintext:  []byte("KnownTxt"),
+		Ciphertext: []byte("NotACiph"),
+		Key:        key,
+		Mask:       mask,
+		Progress:   func(p SearchProgress) { last = p },
+	})
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("ошибка %v", err)
+	}
+	if result.Total != 1<<14 || result.Tested != result.Total || last.Tested != result.Total {
+		t.Errorf("проверено %d из %d, последний отчет %+v", result.Tested, result.Total, last)
+	}
+}
+
+// TestSearchKeyCancel проверяет отмену перебора по контексту, периодические отчеты и то, что при маске
+// на все 56 бит свойство дополнения вдвое сокращает число кандидатов
+func TestSearchKeyCancel(t *testing.T) {
+	key := FixParity([]byte("Brute_8K"))
+	plain := []byte("KnownTxt")
+	d := NewMyDES("01234567", WithWorkers(2))
+	cipher, complement := knownPair(d, key, plain)
+
+	var reports atomic.Int32
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	all := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	result, err := d.SearchKey(ctx, KeySearch{
+		Plaintext:            plain,
+		Ciphertext:           cipher,
+		ComplementCiphertext: complement,
+		Key:                  make([]byte, BlockSize),
+		Mask:                 all,
+		Progress:             func(SearchProgress) { reports.Add(1) },
+		ProgressInterval:     20 * time.Millisecond,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ошибка %v", err)
+	}
+	if result.Space != 1<<56 || result.Total != 1<<55 {
+		t.Errorf("пространство %d, кандидатов %d", result.Space, result.Total)
+	}
+	if result.Tested == 0 || result.Tested >= result.Total || result.Key != nil {
+		t.Errorf("проверено %d, ключ %X", result.Tested, result.Key)
+	}
+	if reports.Load() < 2 {
+		t.Errorf("отчетов о ходе перебора: %d", reports.Load())
+	}
+}
+
+// TestSearchKeyErrors проверяет ошибки параметров перебора
+func TestSearchKeyErrors(t *testing.T) {
+	block := make([]byte, BlockSize)
+	tests := []struct {
+		name string
+		d    *MyDES
+		s    KeySearch
+		want error
+	}{
+		{"parity-only mask", NewMyDES(""), KeySearch{Plaintext: block, Ciphertext: block, Key: block, Mask: []byte{1, 1, 1, 1, 1, 1, 1, 1}}, ErrSearchMask},
+		{"short plaintext", NewMyDES(""), KeySearch{Plaintext: block[:4], Ciphertext: block, Key: block, Mask: block}, ErrSearchPair},
+		{"short complement", NewMyDES(""), KeySearch{Plaintext: block, Ciphertext: block, ComplementCiphertext: block[:1], Key: block, Mask: block}, ErrSearchPair},
+		{"rounds", NewMyDES("", WithRounds(MaxRounds+1)), KeySearch{}, RoundsError(MaxRounds + 1)},
+	}
+	for _, tc := range tests {
+		if _, err := tc.d.SearchKey(context.Background(), tc.s); !errors.Is(err, tc.want) {
+			t.Errorf("%s: ошибка %v, ожидалась %v", tc.name, err, tc.want)
+		}
+	}
+}
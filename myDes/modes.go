@@ -0,0 +1,148 @@
+package myDes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// Mode определяет режим работы блочного шифра в Encode и Decode
+type Mode int
+
+const (
+	ModeCBC   Mode = iota // Сцепление блоков шифротекста (по умолчанию)
+	ModeECB               // Электронная кодовая книга: каждый блок шифруется независимо
+	ModeCFB8              // Обратная связь по шифротексту с шагом 8 бит
+	ModeCFB64             // Обратная связь по шифротексту с шагом 64 бита
+	ModeOFB               // Обратная связь по выходу
+	ModeCTR               // Режим счетчика
+)
+
+// String возвращает название режима
+func (m Mode) String() string {
+	switch m {
+	case ModeCBC:
+		return "CBC"
+	case ModeECB:
+		return "ECB"
+	case ModeCFB8:
+		return "CFB-8"
+	case ModeCFB64:
+		return "CFB-64"
+	case ModeOFB:
+		return "OFB"
+	case ModeCTR:
+		return "CTR"
+	default:
+		return "unknown"
+	}
+}
+
+// IsStream сообщает, превращает ли режим блочный шифр в потоковый.
+// Такие режимы не требуют дополнения, и длина шифротекста равна длине открытого текста
+func (m Mode) IsStream() bool {
+	return m != ModeCBC && m != ModeECB
+}
+
+// cryptBlocks шифрует или расшифровывает данные в режиме d.mode, используя блочный шифр b.
+// Для режимов ECB и CBC длина src должна быть кратна BlockSize
+func (d *MyDES) cryptBlocks(b cipher.Block, iv uint64, src []byte, isDecode bool) []byte {
+	dst := make([]byte, len(src))
+	newModeCrypter(b, d.mode, iv, isDecode, d.workers).crypt(dst, src)
+	return dst
+}
+
+// modeCrypter хранит состояние режима между вызовами crypt, поэтому данные можно обрабатывать по частям.
+// Все части, кроме последней, должны иметь длину, кратную BlockSize
+type modeCrypter struct {
+	b        cipher.Block // Блочный шифр
+	mode     Mode         // Режим работы
+	register uint64       // Предыдущий блок шифротекста (CBC), регистр сдвига (CFB, OFB) или счетчик (CTR)
+	isDecode bool         // Направление обработки
+	workers  int          // Число горутин для режимов ECB и CTR (0 - runtime.NumCPU())
+}
+
+// newModeCrypter создает состояние режима mode с начальным значением iv
+func newModeCrypter(b cipher.Block, mode Mode, iv uint64, isDecode bool, workers int) *modeCrypter {
+	return &modeCrypter{b: b, mode: mode, register: iv, isDecode: isDecode, workers: workers}
+}
+
+// crypt обрабатывает src и записывает результат в dst, продолжая цепочку предыдущих вызовов
+func (c *modeCrypter) crypt(dst, src []byte) {
+	b, isDecode := c.b, c.isDecode
+
+	switch c.mode {
+	case ModeECB, ModeCTR:
+		// Блоки обрабатываются независимо друг от друга, поэтому их можно распределить по горутинам
+		c.cryptParallel(dst, src)
+
+	case ModeCBC:
+		// Используем IV как предыдущий блок для первой итерации
+		previousBlock := c.register
+		for i := 0; i < len(src); i += BlockSize {
+			block := binary.BigEndian.Uint64(src[i:])
+			if isDecode {
+				// Расшифровываем блок и выполняем операцию XOR с предыдущим блоком шифротекста
+				binary.BigEndian.PutUint64(dst[i:], cryptUint64(b, block, true)^previousBlock)
+				previousBlock = block
+			} else {
+				// Выполняем операцию XOR с предыдущим блоком шифротекста и шифруем результат
+				previousBlock = cryptUint64(b, block^previousBlock, false)
+				binary.BigEndian.PutUint64(dst[i:], previousBlock)
+			}
+		}
+		c.register = previousBlock
+
+	case ModeCFB8:
+		// Сдвиговый регистр пополняется одним байтом шифротекста за шаг
+		register := c.register
+		for i := range src {
+			cipherByte := src[i]
+			dst[i] = src[i] ^ byte(cryptUint64(b, register, false)>>56)
+			if !isDecode {
+				cipherByte = dst[i]
+			}
+			register = register<<8 | uint64(cipherByte)
+		}
+		c.register = register
+
+	case ModeCFB64:
+		// Регистр заменяется целым блоком шифротекста
+		register := c.register
+		for i := 0; i < len(src); i += BlockSize {
+			keyStream := cryptUint64(b, register, false)
+			if len(src)-i < BlockSize {
+				// Неполный последний блок не меняет регистр
+				xorKeyStream(dst[i:], src[i:], keyStream)
+				break
+			}
+			// Блок шифротекста читается до записи в dst, чтобы dst и src могли совпадать
+			if isDecode {
+				register = binary.BigEndian.Uint64(src[i:])
+			}
+			xorKeyStream(dst[i:], src[i:], keyStream)
+			if !isDecode {
+				register = binary.BigEndian.Uint64(dst[i:])
+			}
+		}
+		c.register = register
+
+	case ModeOFB:
+		// Гамма получается многократным шифрованием вектора инициализации
+		register := c.register
+		for i := 0; i < len(src); i += BlockSize {
+			register = cryptUint64(b, register, false)
+			xorKeyStream(dst[i:], src[i:], register)
+		}
+		c.register = register
+	}
+}
+
+// xorKeyStream накладывает на до 8 байт src блок гаммы и записывает результат в dst.
+// Возвращает количество обработанных байт
+func xorKeyStream(dst, src []byte, keyStream uint64) int {
+	n := min(len(src), BlockSize)
+	for j := 0; j < n; j++ {
+		dst[j] = src[j] ^ byte(keyStream>>(56-8*j))
+	}
+	return n
+}
@@ -0,0 +1,106 @@
+package myDes
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/binary"
+	"testing"
+)
+
+// TestModesMatchStdlib сверяет режимы с реализациями из crypto/cipher поверх crypto/des
+func TestModesMatchStdlib(t *testing.T) {
+	key, iv := []byte("12345678"), []byte("01234567")
+	plain := []byte("Режимы работы блочного шифра: ECB, CBC, CFB, OFB, CTR")
+	padded := append(append([]byte(nil), plain...), make([]byte, (BlockSize-len(plain)%BlockSize)%BlockSize)...)
+
+	reference, err := des.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[Mode][]byte{}
+	want[ModeECB] = make([]byte, len(padded))
+	for i := 0; i < len(padded); i += BlockSize {
+		reference.Encrypt(want[ModeECB][i:], padded[i:])
+	}
+	want[ModeCBC] = make([]byte, len(padded))
+	cipher.NewCBCEncrypter(reference, iv).CryptBlocks(want[ModeCBC], padded)
+	want[ModeCFB64] = make([]byte, len(plain))
+	cipher.NewCFBEncrypter(reference, iv).XORKeyStream(want[ModeCFB64], plain)
+	want[ModeOFB] = make([]byte, len(plain))
+	cipher.NewOFB(reference, iv).XORKeyStream(want[ModeOFB], plain)
+	want[ModeCTR] = make([]byte, len(plain))
+	cipher.NewCTR(reference, iv).XORKeyStream(want[ModeCTR], plain)
+
+	for mode, expected := range want {
+		d := NewMyDES(string(iv), WithMode(mode))
+		src := plain
+		if !mode.IsStream() {
+			src = padded
+		}
+
+		got := d.cryptBlocks(block, binary.BigEndian.Uint64(iv), src, false)
+		if !bytes.Equal(got, expected) {
+			t.Errorf("%s: получено %x, ожидалось %x", mode, got, expected)
+		}
+		if back := d.cryptBlocks(block, binary.BigEndian.Uint64(iv), got, true); !bytes.Equal(back, src) {
+			t.Errorf("%s: расшифровано %q", mode, back)
+		}
+	}
+}
+
+// TestCFB8 проверяет CFB-8: первый байт гаммы берется из E(IV), каждый следующий зависит от шифротекста
+func TestCFB8(t *testing.T) {
+	key, iv := []byte("12345678"), []byte("01234567")
+	plain := []byte("CFB-8 шифрует по одному байту")
+	block, _ := NewCipher(key)
+	reference, _ := des.NewCipher(key)
+
+	d := NewMyDES(string(iv), WithMode(ModeCFB8))
+	got := d.cryptBlocks(block, binary.BigEndian.Uint64(iv), plain, false)
+
+	// Эталон: сдвиговый регистр из 8 байт, на каждом шаге в него вдвигается байт шифротекста
+	register := append([]byte(nil), iv...)
+	want := make([]byte, len(plain))
+	for i := range plain {
+		out := make([]byte, BlockSize)
+		reference.Encrypt(out, register)
+		want[i] = plain[i] ^ out[0]
+		register = append(register[1:], want[i])
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("получено %x, ожидалось %x", got, want)
+	}
+	if back := d.cryptBlocks(block, binary.BigEndian.Uint64(iv), got, true); !bytes.Equal(back, plain) {
+		t.Fatalf("расшифровано %q", back)
+	}
+}
+
+// TestEncodeModes проверяет Encode/Decode во всех режимах и отсутствие дополнения в потоковых режимах
+func TestEncodeModes(t *testing.T) {
+	input := "Stream modes keep the length: 37 bytes"
+	for _, mode := range []Mode{ModeCBC, ModeECB, ModeCFB8, ModeCFB64, ModeOFB, ModeCTR} {
+		d := NewMyDES("01234567", WithMode(mode))
+		cipherText := mustEncode(t, d, input, "Super_Secret_key")
+
+		got, err := d.Decode([]byte(cipherText), "Super_Secret_key")
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if got != input {
+			t.Errorf("%s: Decode = %q, ожидалось %q", mode, got, input)
+		}
+		data, err := d.processingDecodeInput([]byte(cipherText))
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if n := len(data); mode.IsStream() && n != len(input) {
+			t.Errorf("%s: длина шифротекста %d, ожидалось %d", mode, n, len(input))
+		}
+	}
+}
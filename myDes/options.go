@@ -0,0 +1,101 @@
+package myDes
+
+// Algorithm определяет блочный шифр, используемый MyDES при шифровании строк
+type Algorithm int
+
+const (
+	AlgorithmDES       Algorithm = iota // Одинарный DES с 8-байтовым ключом
+	AlgorithmTripleDES                  // Triple DES (EDE) с ключом из 16 или 24 байт
+)
+
+// String возвращает название алгоритма
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmDES:
+		return "DES"
+	case AlgorithmTripleDES:
+		return "3DES"
+	default:
+		return "unknown"
+	}
+}
+
+// Option настраивает экземпляр MyDES при создании
+type Option func(*MyDES)
+
+// WithAlgorithm задает блочный шифр, которым MyDES шифрует данные
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(d *MyDES) {
+		d.algorithm = algorithm
+	}
+}
+
+// WithMode задает режим работы блочного шифра
+func WithMode(mode Mode) Option {
+	return func(d *MyDES) {
+		d.mode = mode
+	}
+}
+
+// WithPadding задает схему дополнения для режимов ECB и CBC
+func WithPadding(padding Padding) Option {
+	return func(d *MyDES) {
+		d.padding = padding
+	}
+}
+
+// WithKDF включает выработку ключа из пароля в Seal с заданной функцией и параметрами (см. PBKDF2 и Scrypt)
+func WithKDF(params KDFParams) Option {
+	return func(d *MyDES) {
+		d.kdf = params
+	}
+}
+
+// WithMAC включает аутентифицированное шифрование: Seal добавляет в контейнер HMAC-SHA256
+// от заголовка и шифротекста, а Open отклоняет контейнеры без кода или с неверным кодом.
+// Ключ шифрования и ключ HMAC вырабатываются из переданного ключа с разными метками;
+// WithKeyValidation проверяет оба ключа (см. WithKeyValidation)
+func WithMAC() Option {
+	return func(d *MyDES) {
+		d.mac = true
+	}
+}
+
+// WithWorkers задает число горутин, между которыми распределяются блоки в режимах ECB и CTR.
+// По умолчанию используется runtime.NumCPU(); значение 1 отключает параллельную обработку
+func WithWorkers(n int) Option {
+	return func(d *MyDES) {
+		d.workers = n
+	}
+}
+
+// WithKeyValidation включает в CheckKey, Seal и Open отказ от слабых, полуслабых и возможно слабых ключей
+// (см. ValidateKey). parity задает проверку четности ключей, введенных пользователем; при ParityFix Seal и Open
+// шифруют ключом с исправленной четностью. С WithMAC шифрование использует ключ, выработанный из переданного:
+// переданный ключ проверяется как обычно, а выработанный - на слабость без учета четности
+// (ParityFix тогда влияет только на то, что ключи с разной четностью дают один контейнер)
+func WithKeyValidation(parity Parity) Option {
+	return func(d *MyDES) {
+		d.keyValidation = true
+		d.parity = parity
+	}
+}
+
+// WithVariant задает таблицы варианта DES (см. NewVariant и LoadVariant). nil означает таблицы стандарта.
+// Зашифрованное с вариантом расшифровывается только с тем же вариантом: в заголовке контейнера записывается
+// идентификатор таблиц (см. Variant.ID), и Open с другими таблицами возвращает *MismatchError
+func WithVariant(variant *Variant) Option {
+	return func(d *MyDES) {
+		d.variant = variant
+	}
+}
+
+// WithRounds задает число раундов сети Фейстеля от 1 до MaxRounds вместо 16 (см. NewReducedCipher).
+// Недопустимое число раундов отклоняется с RoundsError в CheckKey, Encode, Decode, Seal, Open, TraceBlock
+// и SearchKey. Число раундов записывается в заголовок контейнера, и Open с другим числом раундов
+// возвращает *MismatchError
+func WithRounds(n int) Option {
+	return func(d *MyDES) {
+		d.rounds = n
+	}
+}
@@ -0,0 +1,118 @@
+package myDes
+
+import "strconv"
+
+// Padding определяет схему дополнения открытого текста до целого числа блоков в режимах ECB и CBC
+type Padding int
+
+const (
+	PaddingPKCS7    Padding = iota // PKCS#7: n байт со значением n (по умолчанию)
+	PaddingISO7816                 // ISO/IEC 7816-4: байт 0x80, затем нули
+	PaddingANSIX923                // ANSI X.923: нули, последний байт - длина дополнения
+	PaddingZero                    // Нулевые байты, как в исходной версии; не отличимы от нулей в конце данных
+)
+
+// String возвращает название схемы дополнения
+func (p Padding) String() string {
+	switch p {
+	case PaddingPKCS7:
+		return "PKCS#7"
+	case PaddingISO7816:
+		return "ISO/IEC 7816-4"
+	case PaddingANSIX923:
+		return "ANSI X.923"
+	case PaddingZero:
+		return "zero"
+	default:
+		return "unknown"
+	}
+}
+
+// PaddingError описывает некорректное дополнение, обнаруженное при расшифровке
+type PaddingError struct {
+	Padding Padding // Ожидаемая схема дополнения
+	Reason  string  // Причина, по которой дополнение отклонено
+}
+
+func (e *PaddingError) Error() string {
+	return "myDes: некорректное дополнение " + e.Padding.String() + ": " + e.Reason
+}
+
+// pad дополняет данные до длины, кратной размеру блока
+func (p Padding) pad(data []byte) []byte {
+	n := BlockSize - len(data)%BlockSize
+	if p == PaddingZero {
+		// Нулевое дополнение не добавляет лишний блок, если данные уже выровнены
+		n %= BlockSize
+	}
+
+	result := make([]byte, len(data)+n)
+	copy(result, data)
+	if n == 0 {
+		return result
+	}
+
+	switch p {
+	case PaddingPKCS7:
+		for i := len(data); i < len(result); i++ {
+			result[i] = byte(n)
+		}
+	case PaddingISO7816:
+		result[len(data)] = 0x80
+	case PaddingANSIX923:
+		result[len(result)-1] = byte(n)
+	}
+	return result
+}
+
+// unpad проверяет и удаляет дополнение из расшифрованных данных
+func (p Padding) unpad(data []byte) ([]byte, error) {
+	if len(data)%BlockSize != 0 {
+		return nil, &PaddingError{Padding: p, Reason: "длина данных " + strconv.Itoa(len(data)) + " не кратна размеру блока"}
+	}
+	if p == PaddingZero {
+		// Удаляем нулевые байты, но не больше, чем могло быть добавлено в последнем блоке
+		end := len(data)
+		for end > 0 && len(data)-end < BlockSize-1 && data[end-1] == 0 {
+			end--
+		}
+		return data[:end], nil
+	}
+	if len(data) == 0 {
+		return nil, &PaddingError{Padding: p, Reason: "нет ни одного блока"}
+	}
+
+	last := data[len(data)-BlockSize:]
+	switch p {
+	case PaddingPKCS7, PaddingANSIX923:
+		n := int(last[BlockSize-1])
+		if n == 0 || n > BlockSize {
+			return nil, &PaddingError{Padding: p, Reason: "недопустимая длина дополнения " + strconv.Itoa(n)}
+		}
+
+		// Все байты дополнения, кроме последнего, должны быть равны n (PKCS#7) или нулю (ANSI X.923)
+		fill := byte(n)
+		if p == PaddingANSIX923 {
+			fill = 0
+		}
+		for _, c := range last[BlockSize-n : BlockSize-1] {
+			if c != fill {
+				return nil, &PaddingError{Padding: p, Reason: "байты дополнения не совпадают с ожидаемыми"}
+			}
+		}
+		return data[:len(data)-n], nil
+
+	case PaddingISO7816:
+		// Дополнение - байт 0x80 и следующие за ним нули в пределах последнего блока
+		i := BlockSize - 1
+		for i >= 0 && last[i] == 0 {
+			i--
+		}
+		if i < 0 || last[i] != 0x80 {
+			return nil, &PaddingError{Padding: p, Reason: "не найден байт-разделитель 0x80"}
+		}
+		return data[:len(data)-BlockSize+i], nil
+	}
+
+	return nil, &PaddingError{Padding: p, Reason: "неизвестная схема дополнения"}
+}
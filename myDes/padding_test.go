@@ -0,0 +1,92 @@
+package myDes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestPaddingRoundTrip проверяет дополнение и его удаление для данных разной длины, в том числе с нулями в конце
+func TestPaddingRoundTrip(t *testing.T) {
+	for _, p := range []Padding{PaddingPKCS7, PaddingISO7816, PaddingANSIX923} {
+		for n := 0; n <= 2*BlockSize; n++ {
+			data := make([]byte, n)
+			for i := range data {
+				data[i] = byte(i % 3) // Каждый третий байт и, возможно, последний - нулевой
+			}
+
+			padded := p.pad(data)
+			if len(padded)%BlockSize != 0 || len(padded) <= n {
+				t.Fatalf("%s: длина %d дополнена до %d", p, n, len(padded))
+			}
+			got, err := p.unpad(padded)
+			if err != nil {
+				t.Fatalf("%s, длина %d: %v", p, n, err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("%s: получено %x, ожидалось %x", p, got, data)
+			}
+		}
+	}
+}
+
+// TestPaddingKnownValues проверяет байты дополнения каждой схемы
+func TestPaddingKnownValues(t *testing.T) {
+	data := []byte("abcde")
+	tests := []struct {
+		padding Padding
+		want    []byte
+	}{
+		{PaddingPKCS7, []byte("abcde\x03\x03\x03")},
+		{PaddingISO7816, []byte("abcde\x80\x00\x00")},
+		{PaddingANSIX923, []byte("abcde\x00\x00\x03")},
+		{PaddingZero, []byte("abcde\x00\x00\x00")},
+	}
+	for _, tt := range tests {
+		if got := tt.padding.pad(data); !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: %x, ожидалось %x", tt.padding, got, tt.want)
+		}
+	}
+}
+
+// TestUnpadRejectsInvalid проверяет, что некорректное дополнение отклоняется ошибкой *PaddingError
+func TestUnpadRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		padding Padding
+		data    []byte
+	}{
+		{PaddingPKCS7, nil},
+		{PaddingPKCS7, []byte("abcdefg")},
+		{PaddingPKCS7, []byte("abcdefg\x00")},
+		{PaddingPKCS7, []byte("abcdefg\x09")},
+		{PaddingPKCS7, []byte("abcde\x03\x02\x03")},
+		{PaddingANSIX923, []byte("abcde\x01\x00\x03")},
+		{PaddingISO7816, []byte("abcde\x00\x00\x00")},
+		{PaddingISO7816, []byte("abcde\x80\x00\x01")},
+	}
+	for _, tt := range tests {
+		_, err := tt.padding.unpad(tt.data)
+		var paddingErr *PaddingError
+		if !errors.As(err, &paddingErr) {
+			t.Errorf("%s: unpad(%x) = %v, ожидалась *PaddingError", tt.padding, tt.data, err)
+		}
+	}
+}
+
+// TestDecodeRestoresTrailingZeros проверяет, что файл, оканчивающийся нулями, восстанавливается без потерь
+func TestDecodeRestoresTrailingZeros(t *testing.T) {
+	input := "binary\x00\xff\x00\x00\x00\x00\x00\x00"
+	d := NewMyDES("01234567")
+	got, err := d.Decode([]byte(mustEncode(t, d, input, "key")), "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != input {
+		t.Fatalf("Decode = %q, ожидалось %q", got, input)
+	}
+
+	// Расшифровка с неверным ключом почти всегда ломает дополнение
+	if _, err := d.Decode([]byte(mustEncode(t, d, input, "key")), "other"); err == nil {
+		t.Fatal("ожидалась ошибка дополнения при неверном ключе")
+	}
+}
@@ -0,0 +1,65 @@
+package myDes
+
+import (
+	"crypto/cipher"
+	"runtime"
+	"sync"
+)
+
+// minParallelBlocks - минимальное число блоков на одну горутину. Для меньших сегментов
+// затраты на запуск горутины сравнимы с шифрованием, и выгоды от параллельности нет
+const minParallelBlocks = 256
+
+// cryptParallel обрабатывает данные в режимах ECB и CTR: делит их на сегменты из целых блоков,
+// обрабатывает сегменты в нескольких горутинах и записывает каждый на его место в dst, сохраняя порядок
+func (c *modeCrypter) cryptParallel(dst, src []byte) {
+	blocks := (len(src) + BlockSize - 1) / BlockSize
+	workers := c.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, blocks/minParallelBlocks)
+
+	if workers <= 1 {
+		c.cryptSegment(c.b, dst, src, c.register)
+	} else {
+		// Размер сегмента кратен BlockSize, поэтому неполный блок CTR может оказаться только в последнем
+		segment := (blocks + workers - 1) / workers * BlockSize
+		var wg sync.WaitGroup
+		for start := 0; start < len(src); start += segment {
+			end := min(start+segment, len(src))
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				// Счетчик сегмента - начальное значение плюс номер его первого блока
+				c.cryptSegment(c.b, dst[start:end], src[start:end], c.register+uint64(start/BlockSize))
+			}(start, end)
+		}
+		wg.Wait()
+	}
+
+	if c.mode == ModeCTR {
+		c.register += uint64(blocks)
+	}
+}
+
+// cryptSegment последовательно обрабатывает один сегмент блоком b; counter - значение счетчика CTR для первого блока
+func (c *modeCrypter) cryptSegment(b cipher.Block, dst, src []byte, counter uint64) {
+	if c.mode == ModeECB {
+		// Каждый блок обрабатывается независимо, вектор инициализации не используется
+		for i := 0; i < len(src); i += BlockSize {
+			if c.isDecode {
+				b.Decrypt(dst[i:], src[i:])
+			} else {
+				b.Encrypt(dst[i:], src[i:])
+			}
+		}
+		return
+	}
+
+	// Гамма получается шифрованием счетчика, начальное значение которого равно IV
+	for i := 0; i < len(src); i += BlockSize {
+		xorKeyStream(dst[i:], src[i:], cryptUint64(b, counter, false))
+		counter++
+	}
+}
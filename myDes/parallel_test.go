@@ -0,0 +1,66 @@
+package myDes
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// TestParallelMatchesSequential проверяет, что параллельная обработка ECB и CTR дает тот же результат,
+// что и последовательная, в том числе при неполном последнем блоке CTR и обработке по частям
+func TestParallelMatchesSequential(t *testing.T) {
+	for _, algorithm := range []Algorithm{AlgorithmDES, AlgorithmTripleDES} {
+		key := map[Algorithm]string{AlgorithmDES: "Secret_8", AlgorithmTripleDES: "Super_Secret_key"}[algorithm]
+		for _, mode := range []Mode{ModeECB, ModeCTR} {
+			for _, size := range []int{8, 2048, 8 * 1031, 8*4096 + 5} {
+				if mode == ModeECB {
+					size -= size % BlockSize
+				}
+				src := []byte(randomInput(int64(size), size))
+				sequential := NewMyDES("", WithAlgorithm(algorithm), WithMode(mode), WithWorkers(1))
+				parallel := NewMyDES("", WithAlgorithm(algorithm), WithMode(mode), WithWorkers(7))
+
+				want := sequential.cryptBlocks(sequential.newBlock(key), 0xFFFFFFFFFFFFFF00, src, false)
+				got := parallel.cryptBlocks(parallel.newBlock(key), 0xFFFFFFFFFFFFFF00, src, false)
+				if !bytes.Equal(got, want) {
+					t.Fatalf("%s/%s, %d байт: результат отличается от последовательного", algorithm, mode, size)
+				}
+
+				// Счетчик должен продолжаться между частями так же, как при обработке целиком
+				c := newModeCrypter(parallel.newBlock(key), mode, 0xFFFFFFFFFFFFFF00, false, 7)
+				half := size / 2 / BlockSize * BlockSize
+				chunked := make([]byte, size)
+				c.crypt(chunked[:half], src[:half])
+				c.crypt(chunked[half:], src[half:])
+				if !bytes.Equal(chunked, want) {
+					t.Fatalf("%s/%s, %d байт: обработка по частям отличается", algorithm, mode, size)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkParallel показывает масштабирование ECB и CTR с числом горутин
+func BenchmarkParallel(b *testing.B) {
+	src := []byte(randomInput(1, 1<<20))
+	// 1, 2, 4, ... и число процессоров, если оно не степень двойки
+	var counts []int
+	for workers := 1; workers < runtime.NumCPU(); workers *= 2 {
+		counts = append(counts, workers)
+	}
+	counts = append(counts, runtime.NumCPU())
+
+	for _, mode := range []Mode{ModeECB, ModeCTR} {
+		for _, workers := range counts {
+			b.Run(fmt.Sprintf("%s/workers=%d", mode, workers), func(b *testing.B) {
+				d := NewMyDES("", WithMode(mode), WithWorkers(workers))
+				block := d.newBlock("Secret_8")
+				b.SetBytes(int64(len(src)))
+				for i := 0; i < b.N; i++ {
+					d.cryptBlocks(block, 0, src, false)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package myDes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// TestReducedRounds сверяет шифр с r раундами с состоянием после раунда r полного DES
+// и проверяет, что расшифровка с обратным порядком подключей восстанавливает блок
+func TestReducedRounds(t *testing.T) {
+	key, plain := "Secret_8", []byte("Rounds!!")
+	full, err := NewMyDES("").TraceBlock(plain, key, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for rounds := 1; rounds <= 16; rounds++ {
+		b, err := NewReducedCipher([]byte(key), rounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, BlockSize)
+		b.Encrypt(got, plain)
+
+		// После r раундов половины меняются местами и проходят конечную перестановку
+		state := full.Rounds[rounds-1]
+		want := standardVariant.fp.apply(uint64(state.NextRight)<<32 | uint64(state.NextLeft))
+		if binary.BigEndian.Uint64(got) != want {
+			t.Errorf("%d раундов: %x, ожидалось %016x", rounds, got, want)
+		}
+
+		b.Decrypt(got, got)
+		if !bytes.Equal(got, plain) {
+			t.Errorf("%d раундов: расшифровано %q", rounds, got)
+		}
+	}
+}
+
+// TestExtendedRounds проверяет, что после 16 раундов подключи повторяются, а расшифровка
+// использует их в обратном порядке
+func TestExtendedRounds(t *testing.T) {
+	key, plain := "Secret_8", []byte("Rounds!!")
+	for _, rounds := range []int{17, 24, 32, MaxRounds} {
+		trace, err := NewMyDES("", WithRounds(rounds)).TraceBlock(plain, key, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trace.Rounds) != rounds {
+			t.Fatalf("записано %d раундов из %d", len(trace.Rounds), rounds)
+		}
+		for i := 16; i < rounds; i++ {
+			if trace.Rounds[i].SubKey != trace.Rounds[i%16].SubKey {
+				t.Errorf("%d раундов: подключ раунда %d не повторяет подключ раунда %d", rounds, i+1, i%16+1)
+			}
+		}
+
+		decoded, err := NewMyDES("", WithRounds(rounds)).TraceBlock(uint64Bytes(trace.Output), key, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Output != binary.BigEndian.Uint64(plain) {
+			t.Errorf("%d раундов: расшифровано %016x", rounds, decoded.Output)
+		}
+	}
+
+	// Triple DES с уменьшенным числом раундов в каждом проходе
+	d := NewMyDES("", WithAlgorithm(AlgorithmTripleDES), WithRounds(6), WithMAC())
+	sealed, err := d.Seal(plain, "Super_Secret_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened, err := d.Open(sealed, "Super_Secret_key"); err != nil || !bytes.Equal(opened, plain) {
+		t.Errorf("Triple DES, 6 раундов: %q, %v", opened, err)
+	}
+	if opened, err := NewMyDES("", WithAlgorithm(AlgorithmTripleDES), WithMAC()).Open(sealed, "Super_Secret_key"); err == nil && bytes.Equal(opened, plain) {
+		t.Error("16-раундовый Triple DES расшифровал шифротекст 6-раундового")
+	}
+}
+
+// TestRoundsValidation проверяет отказ от недопустимого числа раундов
+func TestRoundsValidation(t *testing.T) {
+	var roundsErr RoundsError
+	for _, rounds := range []int{0, -1, MaxRounds + 1} {
+		if _, err := NewReducedCipher([]byte("Secret_8"), rounds); !errors.As(err, &roundsErr) {
+			t.Errorf("NewReducedCipher(%d): %v", rounds, err)
+		}
+	}
+	if err := NewMyDES("", WithRounds(MaxRounds+1)).CheckKey("Secret_8"); !errors.As(err, &roundsErr) {
+		t.Errorf("CheckKey: %v", err)
+	}
+
+	// Encode и Decode не выполняют шифрование без раундов
+	for _, rounds := range []int{-1, MaxRounds + 1} {
+		d := NewMyDES("01234567", WithMode(ModeECB), WithRounds(rounds))
+		if _, err := d.Decode([]byte("8282828282828282"), "Secret_8"); !errors.As(err, &roundsErr) {
+			t.Errorf("Decode, %d раундов: %v", rounds, err)
+		}
+		if encoded, err := d.Encode("AAAAAAAA", "Secret_8"); !errors.As(err, &roundsErr) {
+			t.Errorf("Encode, %d раундов: %q, %v", rounds, encoded, err)
+		}
+	}
+	if _, err := NewReducedCipher([]byte("short"), 4); err == nil {
+		t.Error("принят ключ из 5 байт")
+	}
+}
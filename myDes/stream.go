@@ -0,0 +1,344 @@
+package myDes
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
+)
+
+// streamChunk - размер части данных, которая шифруется или расшифровывается за один шаг
+const streamChunk = 32 * 1024
+
+// ErrClosed возвращается при записи в закрытый поток шифрования
+var ErrClosed = errors.New("myDes: поток шифрования закрыт")
+
+// encrypter шифрует записываемые данные по частям и пишет контейнер в w
+type encrypter struct {
+	w       io.Writer    // Назначение контейнера
+	crypter *modeCrypter // Состояние режима, сохраняющееся между вызовами Write
+	mode    Mode         // Режим работы
+	padding Padding      // Схема дополнения
+	mac     hash.Hash    // Код аутентичности (nil, если не используется)
+
+	buf     []byte // Неполный блок, ожидающий следующих данных
+	scratch []byte // Буфер для шифротекста
+	closed  bool
+	err     error // Первая ошибка записи в w
+}
+
+// NewEncrypter возвращает поток, который шифрует записываемые в него данные и пишет в w контейнер
+// в том же формате, что и Seal. Заголовок записывается сразу, шифротекст - по мере поступления целых блоков.
+// Close дополняет и шифрует последний блок и дописывает код аутентичности, но не закрывает w
+func (d *MyDES) NewEncrypter(w io.Writer, key string) (io.WriteCloser, error) {
+	kdf := d.kdf
+	if kdf.KDF != KDFNone {
+		var err error
+		if kdf, err = kdf.withSalt(); err != nil {
+			return nil, err
+		}
+		derived, err := kdf.DeriveKey(key, d.algorithm.keySize())
+		if err != nil {
+			return nil, err
+		}
+		key = string(derived)
+	}
+	key, err := d.checkKey(key, kdf.KDF != KDFNone)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	// Код аутентичности вычисляется по уже зашифрованным данным (encrypt-then-MAC).
+	// Длина кода входит в заголовок, поэтому место под него резервируется заранее
+	c := &Container{
+		Algorithm: d.algorithm,
+		Mode:      d.mode,
+		Padding:   d.padding,
+		Variant:   d.variant.ID(),
+		Rounds:    uint8(d.roundCount()),
+		IV:        iv,
+		KDF:       kdf,
+	}
+	if d.mac {
+		c.MAC = make([]byte, macSize)
+	}
+	cipherKey, macKey, err := d.containerKeys(c, key)
+	if err != nil {
+		return nil, err
+	}
+	e := &encrypter{
+		w:       w,
+		crypter: newModeCrypter(d.newBlock(cipherKey), d.mode, binary.BigEndian.Uint64(iv), false, d.workers),
+		mode:    d.mode,
+		padding: d.padding,
+		buf:     make([]byte, 0, BlockSize),
+	}
+	if macKey != nil {
+		e.mac = newContainerMAC(c, macKey)
+	}
+
+	if _, err := w.Write(c.Header()); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Write шифрует целые блоки из p и записывает их; остаток до целого блока накапливается до следующего вызова
+func (e *encrypter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, ErrClosed
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	written := len(p)
+	for len(p) > 0 {
+		// Без накопленного остатка целые блоки шифруются прямо из p частями по streamChunk
+		if len(e.buf) == 0 && len(p) >= BlockSize {
+			n := min(len(p)-len(p)%BlockSize, streamChunk)
+			if e.scratch == nil {
+				e.scratch = make([]byte, streamChunk)
+			}
+			e.crypter.crypt(e.scratch[:n], p[:n])
+			if err := e.output(e.scratch[:n]); err != nil {
+				return written - len(p), err
+			}
+			p = p[n:]
+			continue
+		}
+
+		// Иначе дополняем накопленный остаток до целого блока
+		n := copy(e.buf[len(e.buf):BlockSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		if len(e.buf) == BlockSize {
+			e.crypter.crypt(e.buf, e.buf)
+			if err := e.output(e.buf); err != nil {
+				return written - len(p), err
+			}
+			e.buf = e.buf[:0]
+		}
+	}
+	return written, nil
+}
+
+// output записывает шифротекст в w и добавляет его к коду аутентичности
+func (e *encrypter) output(cipherText []byte) error {
+	if e.mac != nil {
+		e.mac.Write(cipherText)
+	}
+	if _, err := e.w.Write(cipherText); err != nil {
+		e.err = err
+		return err
+	}
+	return nil
+}
+
+// Close шифрует остаток данных (в блочных режимах - с дополнением) и дописывает код аутентичности
+func (e *encrypter) Close() error {
+	if e.closed {
+		return e.err
+	}
+	e.closed = true
+	if e.err != nil {
+		return e.err
+	}
+
+	last := e.buf
+	if !e.mode.IsStream() {
+		last = e.padding.pad(e.buf)
+	}
+	e.crypter.crypt(last, last)
+	if err := e.output(last); err != nil {
+		return err
+	}
+
+	if e.mac != nil {
+		if _, err := e.w.Write(e.mac.Sum(nil)); err != nil {
+			e.err = err
+		}
+	}
+	return e.err
+}
+
+// decrypter расшифровывает контейнер, читая его из r по частям
+type decrypter struct {
+	r       io.Reader    // Источник контейнера
+	crypter *modeCrypter // Состояние режима, сохраняющееся между вызовами Read
+	mode    Mode         // Режим работы
+	padding Padding      // Схема дополнения
+	mac     hash.Hash    // Код аутентичности (nil, если контейнер его не содержит)
+	macLen  int          // Длина кода аутентичности в конце контейнера
+
+	pending   []byte // Прочитанные, но еще не расшифрованные байты
+	out       []byte // Расшифрованные данные, еще не отданные вызывающему
+	buf       []byte // Буфер для расшифрованных данных
+	processed int    // Количество уже расшифрованных байт шифротекста
+	err       error  // Ошибка или io.EOF, которые вернутся после того, как out будет прочитан
+}
+
+// NewDecrypter читает заголовок контейнера из r и возвращает поток, расшифровывающий остальную часть.
+// Параметры берутся из заголовка, как в Open. Код аутентичности находится в конце контейнера, поэтому
+// он проверяется только при достижении конца данных: если поток вернул ошибку, все прочитанное
+// из него ранее нужно отбросить как непроверенное
+func (d *MyDES) NewDecrypter(r io.Reader, key string) (io.Reader, error) {
+	var c Container
+	macLen, err := c.readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	// Таблицы и число раундов в заголовке только сверяются с настройками d: таблицы по идентификатору
+	// не восстановить, а число раундов, как и таблицы, выбирается при создании MyDES.
+	// Проверка идет до выработки ключа из пароля, которая может быть долгой
+	if err := d.checkRounds(); err != nil {
+		return nil, err
+	}
+	switch {
+	case c.Variant != d.variant.ID():
+		return nil, &MismatchError{Reason: "таблицы DES"}
+	case int(c.Rounds) != d.roundCount():
+		return nil, &MismatchError{Reason: "число раундов " + strconv.Itoa(int(c.Rounds)) + ", а не " + strconv.Itoa(d.roundCount())}
+	}
+
+	// Если ключ вырабатывался из пароля, повторяем выработку с солью и параметрами из заголовка
+	if c.KDF.KDF != KDFNone {
+		derived, err := c.KDF.DeriveKey(key, c.Algorithm.keySize())
+		if err != nil {
+			return nil, err
+		}
+		key = string(derived)
+	}
+
+	// Алгоритм берется из заголовка, а проверка ключа, вариант таблиц и число раундов - из настроек d
+	opened := NewMyDES(string(c.IV), WithAlgorithm(c.Algorithm), WithMode(c.Mode), WithPadding(c.Padding))
+	opened.keyValidation, opened.parity, opened.variant, opened.rounds = d.keyValidation, d.parity, d.variant, d.rounds
+	if key, err = opened.checkKey(key, c.KDF.KDF != KDFNone); err != nil {
+		return nil, err
+	}
+
+	// Контейнер без кода аутентичности допускается, только если он не требуется
+	if macLen > 0 {
+		c.MAC = make([]byte, macLen)
+	} else if d.mac {
+		return nil, &IntegrityError{Reason: "контейнер не содержит кода аутентичности"}
+	}
+	cipherKey, macKey, err := opened.containerKeys(&c, key)
+	if err != nil {
+		return nil, err
+	}
+
+	dr := &decrypter{
+		r:       r,
+		crypter: newModeCrypter(opened.newBlock(cipherKey), c.Mode, binary.BigEndian.Uint64(c.IV), true, d.workers),
+		mode:    c.Mode,
+		padding: c.Padding,
+		macLen:  macLen,
+		pending: make([]byte, 0, streamChunk+macLen+BlockSize),
+		buf:     make([]byte, streamChunk+BlockSize),
+	}
+	if macKey != nil {
+		dr.mac = newContainerMAC(&c, macKey)
+	}
+	return dr, nil
+}
+
+// Read возвращает очередную часть открытого текста
+func (dr *decrypter) Read(p []byte) (int, error) {
+	for len(dr.out) == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		dr.fill()
+	}
+
+	n := copy(p, dr.out)
+	dr.out = dr.out[n:]
+	return n, nil
+}
+
+// fill читает следующую часть контейнера и расшифровывает все блоки, кроме тех, что могут оказаться
+// кодом аутентичности или последним дополненным блоком
+func (dr *decrypter) fill() {
+	n, err := dr.r.Read(dr.pending[len(dr.pending):cap(dr.pending)])
+	dr.pending = dr.pending[:len(dr.pending)+n]
+	switch {
+	case err == io.EOF:
+		dr.finish()
+		return
+	case err != nil:
+		dr.err = err
+		return
+	}
+
+	// Последние macLen байт могут оказаться кодом аутентичности, а в блочных режимах
+	// последний блок нельзя расшифровать до конца данных, так как он содержит дополнение
+	ready := len(dr.pending) - dr.macLen
+	if !dr.mode.IsStream() {
+		ready--
+	}
+	if ready < BlockSize {
+		return
+	}
+	ready -= ready % BlockSize
+
+	dr.decrypt(dr.pending[:ready])
+	dr.pending = append(dr.pending[:0], dr.pending[ready:]...)
+}
+
+// decrypt расшифровывает часть шифротекста в dr.out
+func (dr *decrypter) decrypt(cipherText []byte) {
+	if dr.mac != nil {
+		dr.mac.Write(cipherText)
+	}
+	dr.out = dr.buf[:len(cipherText)]
+	dr.crypter.crypt(dr.out, cipherText)
+	dr.processed += len(cipherText)
+}
+
+// finish обрабатывает конец контейнера: проверяет код аутентичности, длину шифротекста и дополнение
+func (dr *decrypter) finish() {
+	if len(dr.pending) < dr.macLen {
+		dr.err = &ContainerError{Reason: "код аутентичности обрезан"}
+		return
+	}
+	cipherText, tag := dr.pending[:len(dr.pending)-dr.macLen], dr.pending[len(dr.pending)-dr.macLen:]
+
+	// Проверяем целостность до того, как расшифровать последний блок
+	if dr.mac != nil {
+		dr.mac.Write(cipherText)
+		if !hmac.Equal(tag, dr.mac.Sum(nil)) {
+			dr.err = &IntegrityError{Reason: "код аутентичности не совпадает: файл изменен или обрезан, либо ключ неверен"}
+			return
+		}
+		dr.mac = nil
+	}
+
+	// В блочных режимах шифротекст состоит из целых блоков
+	if !dr.mode.IsStream() && len(cipherText)%BlockSize != 0 {
+		total := dr.processed + len(cipherText)
+		dr.err = &BlockLengthError{Block: total / BlockSize, Length: total % BlockSize}
+		return
+	}
+
+	dr.decrypt(cipherText)
+	dr.err = io.EOF
+	if !dr.mode.IsStream() {
+		var err error
+		if dr.out, err = dr.padding.unpad(dr.out); err != nil {
+			dr.out, dr.err = nil, err
+		}
+	}
+}
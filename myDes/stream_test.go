@@ -0,0 +1,81 @@
+package myDes
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// TestStreamRoundTrip проверяет, что запись по частям произвольного размера дает контейнер,
+// который расшифровывается Open и потоком, читающим по одному байту
+func TestStreamRoundTrip(t *testing.T) {
+	plain := []byte(randomInput(7, 3*streamChunk+13))
+	for _, mode := range []Mode{ModeCBC, ModeECB, ModeCFB8, ModeCFB64, ModeOFB, ModeCTR} {
+		d := NewMyDES("", WithMode(mode), WithMAC())
+		for _, chunk := range []int{1, 5, 8, 1000, streamChunk + 3} {
+			var buf bytes.Buffer
+			e, err := d.NewEncrypter(&buf, "Secret_8")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < len(plain); i += chunk {
+				if _, err := e.Write(plain[i:min(i+chunk, len(plain))]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := d.Open(buf.Bytes(), "Secret_8")
+			if err != nil || !bytes.Equal(got, plain) {
+				t.Fatalf("%s, части по %d байт: Open = %d байт, %v", mode, chunk, len(got), err)
+			}
+
+			r, err := d.NewDecrypter(iotest.OneByteReader(bytes.NewReader(buf.Bytes())), "Secret_8")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err = io.ReadAll(iotest.DataErrReader(r))
+			if err != nil || !bytes.Equal(got, plain) {
+				t.Fatalf("%s, части по %d байт: поток вернул %d байт, %v", mode, chunk, len(got), err)
+			}
+		}
+	}
+}
+
+// TestStreamIntegrity проверяет, что поток сообщает об изменении последнего байта шифротекста
+func TestStreamIntegrity(t *testing.T) {
+	plain := []byte(randomInput(8, 2*streamChunk))
+	d := NewMyDES("", WithMAC())
+	sealed, err := d.Seal(plain, "Secret_8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[len(sealed)-macSize-1] ^= 1
+
+	r, err := d.NewDecrypter(bytes.NewReader(sealed), "Secret_8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var integrityErr *IntegrityError
+	if _, err := io.ReadAll(r); !errors.As(err, &integrityErr) {
+		t.Fatalf("ошибка %v, ожидалась ошибка целостности", err)
+	}
+}
+
+// TestEncrypterClosed проверяет запись после Close
+func TestEncrypterClosed(t *testing.T) {
+	e, err := NewMyDES("").NewEncrypter(io.Discard, "Secret_8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write([]byte("data")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("запись после Close: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package myDes
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Tables - таблицы варианта DES в нумерации стандарта: биты нумеруются с 1, начиная со старшего.
+// Конечная перестановка не задается: она вычисляется как обратная к начальной
+type Tables struct {
+	Name   string    `json:"name"`   // Название варианта
+	IP     []int     `json:"ip"`     // Начальная перестановка: 64 различных номера от 1 до 64
+	E      []int     `json:"e"`      // Таблица расширения: 48 номеров битов правой половины от 1 до 32
+	P      []int     `json:"p"`      // Перестановка P: 32 различных номера от 1 до 32
+	PC1    []int     `json:"pc1"`    // Перестановка PC-1: 56 различных номеров битов ключа от 1 до 64
+	PC2    []int     `json:"pc2"`    // Перестановка PC-2: 48 различных номеров от 1 до 56
+	Shifts []int     `json:"shifts"` // Циклические сдвиги половин ключа в каждом из 16 раундов
+	SBoxes [][][]int `json:"sboxes"` // 8 S-блоков по 4 строки из 16 значений от 0 до 15
+}
+
+// TablesError описывает ошибку в таблицах варианта DES
+type TablesError struct {
+	Table  string // Название таблицы (ip, e, p, pc1, pc2, shifts, sboxes) или json при ошибке разбора файла
+	Reason string // Описание нарушения
+}
+
+func (e *TablesError) Error() string {
+	return "myDes: таблица " + e.Table + ": " + e.Reason
+}
+
+// Variant - проверенный вариант DES с предвычисленными таблицами (см. NewVariant и WithVariant).
+// Вариант не изменяется после создания, и его можно использовать из нескольких горутин
+type Variant struct {
+	tables Tables // Копия исходных таблиц
+	ip, fp permutation
+	e, p   permutation
+	pc1    permutation
+	pc2    permutation
+	shifts [16]int // Сдвиг половин ключа в каждом раунде
+	spin   [16]int // Суммарный сдвиг половин ключа к каждому раунду (по модулю 28)
+	sBox   [8][64]uint8
+	id     [8]byte // Идентификатор таблиц (см. ID)
+}
+
+// standardVariant - таблицы стандарта DES (FIPS 46-3), используемые по умолчанию
+var standardVariant = mustVariant(StandardTables())
+
+// StandardTables возвращает копию таблиц стандарта DES, например как основу для своего варианта
+func StandardTables() *Tables {
+	t := &Tables{
+		Name: "standard",
+		IP:   append([]int(nil), initReplaceTable...),
+		E:    append([]int(nil), extendTable...),
+		P:    append([]int(nil), pBoxReplaceTable...),
+		PC1:  append([]int(nil), keyReplaceTable...),
+		PC2:  append([]int(nil), keySelectTable...),
+	}
+	// В spinTable хранятся суммарные сдвиги, в файле удобнее сдвиг каждого раунда
+	previous := 0
+	for _, shift := range spinTable {
+		t.Shifts = append(t.Shifts, shift-previous)
+		previous = shift
+	}
+	for _, box := range sBoxTable {
+		rows := make([][]int, len(box))
+		for i, row := range box {
+			for _, v := range row {
+				rows[i] = append(rows[i], int(v))
+			}
+		}
+		t.SBoxes = append(t.SBoxes, rows)
+	}
+	return t
+}
+
+// mustVariant создает вариант из заведомо верных таблиц
+func mustVariant(t *Tables) *Variant {
+	v, err := NewVariant(t)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// LoadVariant читает таблицы варианта DES в формате JSON и проверяет их (см. NewVariant).
+// Неизвестные поля считаются ошибкой, чтобы опечатка в названии таблицы не осталась незамеченной
+func LoadVariant(r io.Reader) (*Variant, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var t Tables
+	if err := decoder.Decode(&t); err != nil {
+		return nil, &TablesError{Table: "json", Reason: err.Error()}
+	}
+	return NewVariant(&t)
+}
+
+// NewVariant проверяет таблицы и предвычисляет по ним перестановки и S-блоки.
+// Начальная перестановка, P, PC-1 и PC-2 должны быть взаимно однозначными, таблица расширения -
+// ссылаться только на биты правой половины, S-блоки - иметь форму 8x4x16 со значениями от 0 до 15.
+// При нарушении возвращается *TablesError
+func NewVariant(t *Tables) (*Variant, error) {
+	for _, check := range []struct {
+		name     string
+		table    []int
+		size     int  // Ожидаемое число элементов
+		max      int  // Наибольший допустимый номер бита
+		distinct bool // Номера не должны повторяться
+	}{
+		{"ip", t.IP, 64, 64, true},
+		{"e", t.E, 48, 32, false},
+		{"p", t.P, 32, 32, true},
+		{"pc1", t.PC1, 56, 64, true},
+		{"pc2", t.PC2, 48, 56, true},
+	} {
+		if err := checkTable(check.name, check.table, check.size, check.max, check.distinct); err != nil {
+			return nil, err
+		}
+	}
+
+	v := &Variant{
+		tables: *t.clone(),
+		ip:     newPermutation(t.IP, 64),
+		fp:     newPermutation(InversePermutation(t.IP), 64),
+		e:      newPermutation(t.E, 32),
+		p:      newPermutation(t.P, 32),
+		pc1:    newPermutation(t.PC1, 64),
+		pc2:    newPermutation(t.PC2, 56),
+	}
+
+	if len(t.Shifts) != len(v.spin) {
+		return nil, &TablesError{Table: "shifts", Reason: fmt.Sprintf("ожидалось 16 сдвигов, получено %d", len(t.Shifts))}
+	}
+	total := 0
+	for i, shift := range t.Shifts {
+		if shift < 0 || shift > 27 {
+			return nil, &TablesError{Table: "shifts", Reason: fmt.Sprintf("сдвиг раунда %d вне диапазона 0-27: %d", i+1, shift)}
+		}
+		total += shift
+		v.shifts[i], v.spin[i] = shift, total%28
+	}
+
+	var boxes [8][4][16]uint8
+	if len(t.SBoxes) != len(boxes) {
+		return nil, &TablesError{Table: "sboxes", Reason: fmt.Sprintf("ожидалось 8 S-блоков, получено %d", len(t.SBoxes))}
+	}
+	for i, box := range t.SBoxes {
+		if len(box) != 4 {
+			return nil, &TablesError{Table: "sboxes", Reason: fmt.Sprintf("S%d: ожидалось 4 строки, получено %d", i+1, len(box))}
+		}
+		for row, values := range box {
+			if len(values) != 16 {
+				return nil, &TablesError{Table: "sboxes", Reason: fmt.Sprintf("S%d, строка %d: ожидалось 16 значений, получено %d", i+1, row, len(values))}
+			}
+			for column, value := range values {
+				if value < 0 || value > 15 {
+					return nil, &TablesError{Table: "sboxes", Reason: fmt.Sprintf("S%d, строка %d, столбец %d: значение %d вне диапазона 0-15", i+1, row, column, value)}
+				}
+				boxes[i][row][column] = uint8(value)
+			}
+		}
+	}
+	v.sBox = newSBoxLookup(boxes)
+	v.id = tablesID(t)
+	return v, nil
+}
+
+// Name возвращает название варианта
+func (v *Variant) Name() string {
+	return v.tables.Name
+}
+
+// ID возвращает идентификатор таблиц варианта. Он записывается в заголовок контейнера, чтобы контейнер
+// нельзя было расшифровать с другими таблицами. Для nil возвращается идентификатор таблиц стандарта
+func (v *Variant) ID() [8]byte {
+	if v == nil {
+		return standardVariant.id
+	}
+	return v.id
+}
+
+// SBoxLookup возвращает S-блоки варианта, индексируемые сразу 6-битным входом: строку задают крайние биты входа,
+// столбец - четыре средних. Для nil возвращаются S-блоки стандарта
+func (v *Variant) SBoxLookup() [8][64]uint8 {
+	if v == nil {
+		return standardVariant.sBox
+	}
+	return v.sBox
+}
+
+// Tables возвращает копию таблиц варианта. Для nil возвращаются таблицы стандарта
+func (v *Variant) Tables() *Tables {
+	if v == nil {
+		return StandardTables()
+	}
+	return v.tables.clone()
+}
+
+// NewCipher создает блочный шифр DES варианта v с 8-байтовым ключом и числом раундов от 1 до MaxRounds
+// (см. NewReducedCipher). Для nil используются таблицы стандарта
+func (v *Variant) NewCipher(key []byte, rounds int) (cipher.Block, error) {
+	if rounds < 1 || rounds > MaxRounds {
+		return nil, RoundsError(rounds)
+	}
+	if len(key) != BlockSize {
+		return nil, KeySizeError(len(key))
+	}
+	return newDESBlock(string(key), v, rounds), nil
+}
+
+// clone возвращает глубокую копию таблиц
+func (t *Tables) clone() *Tables {
+	c := *t
+	for _, table := range []*[]int{&c.IP, &c.E, &c.P, &c.PC1, &c.PC2, &c.Shifts} {
+		*table = append([]int(nil), *table...)
+	}
+	c.SBoxes = make([][][]int, len(t.SBoxes))
+	for i, box := range t.SBoxes {
+		c.SBoxes[i] = make([][]int, len(box))
+		for row, values := range box {
+			c.SBoxes[i][row] = append([]int(nil), values...)
+		}
+	}
+	return &c
+}
+
+// tablesID вычисляет идентификатор таблиц - первые 8 байт SHA-256 от их записи в JSON. Название в запись
+// не входит, поэтому одинаковые таблицы под разными названиями получают один идентификатор
+func tablesID(t *Tables) [8]byte {
+	c := *t
+	c.Name = ""
+	// Таблицы состоят только из чисел, поэтому запись в JSON не может завершиться ошибкой
+	data, _ := json.Marshal(&c)
+	sum := sha256.Sum256(data)
+	return [8]byte(sum[:8])
+}
+
+// checkTable проверяет число элементов таблицы, диапазон номеров битов и, если distinct, отсутствие повторов
+func checkTable(name string, table []int, size, max int, distinct bool) error {
+	if len(table) != size {
+		return &TablesError{Table: name, Reason: fmt.Sprintf("ожидалось %d элементов, получено %d", size, len(table))}
+	}
+	seen := make([]bool, max+1)
+	for i, bit := range table {
+		if bit < 1 || bit > max {
+			return &TablesError{Table: name, Reason: fmt.Sprintf("элемент %d: номер бита %d вне диапазона 1-%d", i+1, bit, max)}
+		}
+		if distinct && seen[bit] {
+			return &TablesError{Table: name, Reason: fmt.Sprintf("элемент %d: бит %d уже использован, перестановка не взаимно однозначна", i+1, bit)}
+		}
+		seen[bit] = true
+	}
+	return nil
+}
+
+// InversePermutation строит таблицу обратной перестановки для взаимно однозначной таблицы в нумерации Permute
+func InversePermutation(table []int) []int {
+	inverse := make([]int, len(table))
+	for i, bit := range table {
+		inverse[bit-1] = i + 1
+	}
+	return inverse
+}
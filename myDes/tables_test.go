@@ -0,0 +1,104 @@
+package myDes
+
+import (
+	"bytes"
+	"crypto/des"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestStandardVariant проверяет, что таблицы стандарта, прочитанные из JSON, дают тот же шифр, что и crypto/des
+func TestStandardVariant(t *testing.T) {
+	data, err := json.Marshal(StandardTables())
+	if err != nil {
+		t.Fatal(err)
+	}
+	variant, err := LoadVariant(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reference, _ := des.NewCipher([]byte("Secret_8"))
+	want := make([]byte, BlockSize)
+	reference.Encrypt(want, []byte("Variants"))
+
+	got := make([]byte, BlockSize)
+	newDESBlock("Secret_8", variant, 0).Encrypt(got, []byte("Variants"))
+	if !bytes.Equal(got, want) {
+		t.Errorf("шифротекст %x, ожидалось %x", got, want)
+	}
+}
+
+// TestCustomVariant проверяет, что вариант с другими S-блоками меняет шифротекст и расшифровывает его обратно
+func TestCustomVariant(t *testing.T) {
+	tables := StandardTables()
+	tables.Name = "swapped"
+	tables.SBoxes[0], tables.SBoxes[7] = tables.SBoxes[7], tables.SBoxes[0]
+	variant, err := NewVariant(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := "Вариант DES с другими S-блоками"
+	custom := NewMyDES("01234567", WithVariant(variant))
+	encoded := mustEncode(t, custom, plain, "Secret_8")
+	if encoded == mustEncode(t, NewMyDES("01234567"), plain, "Secret_8") {
+		t.Error("шифротекст совпал с шифротекстом стандарта")
+	}
+	if decoded, err := custom.Decode([]byte(encoded), "Secret_8"); err != nil || decoded != plain {
+		t.Errorf("расшифровано %q, %v", decoded, err)
+	}
+
+	for _, algorithm := range []Algorithm{AlgorithmDES, AlgorithmTripleDES} {
+		d := NewMyDES("", WithVariant(variant), WithAlgorithm(algorithm), WithMAC())
+		key := "Super_Secret_key"[:8*(int(algorithm)+1)]
+		sealed, err := d.Seal([]byte(plain), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opened, err := d.Open(sealed, key); err != nil || string(opened) != plain {
+			t.Errorf("%s: Open = %q, %v", algorithm, opened, err)
+		}
+	}
+}
+
+// TestVariantValidation проверяет отказ от неверных таблиц
+func TestVariantValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Tables)
+		table  string
+	}{
+		{"повтор в IP", func(tb *Tables) { tb.IP[1] = tb.IP[0] }, "ip"},
+		{"короткая P", func(tb *Tables) { tb.P = tb.P[:31] }, "p"},
+		{"E вне правой половины", func(tb *Tables) { tb.E[0] = 33 }, "e"},
+		{"повтор в PC-2", func(tb *Tables) { tb.PC2[5] = tb.PC2[6] }, "pc2"},
+		{"PC-1 с номером 0", func(tb *Tables) { tb.PC1[0] = 0 }, "pc1"},
+		{"15 сдвигов", func(tb *Tables) { tb.Shifts = tb.Shifts[1:] }, "shifts"},
+		{"7 S-блоков", func(tb *Tables) { tb.SBoxes = tb.SBoxes[1:] }, "sboxes"},
+		{"строка из 15 значений", func(tb *Tables) { tb.SBoxes[3][2] = tb.SBoxes[3][2][:15] }, "sboxes"},
+		{"значение 16", func(tb *Tables) { tb.SBoxes[0][0][0] = 16 }, "sboxes"},
+	}
+	for _, tt := range tests {
+		tables := StandardTables()
+		tt.modify(tables)
+		var tablesErr *TablesError
+		if _, err := NewVariant(tables); !errors.As(err, &tablesErr) || tablesErr.Table != tt.table {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+
+	// E может повторять биты, а S-блоки не обязаны быть перестановками: такие варианты допустимы
+	tables := StandardTables()
+	tables.E[0] = tables.E[1]
+	tables.SBoxes[0][0][0] = tables.SBoxes[0][0][1]
+	if _, err := NewVariant(tables); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := LoadVariant(strings.NewReader(`{"name": "x", "pc3": []}`)); err == nil {
+		t.Error("принято неизвестное поле")
+	}
+}
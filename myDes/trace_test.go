@@ -0,0 +1,86 @@
+package myDes
+
+import (
+	"crypto/des"
+	"encoding/binary"
+	"testing"
+)
+
+// TestTraceBlock сверяет трассировку с эталонными раундами и результатом crypto/des
+func TestTraceBlock(t *testing.T) {
+	v := tableA2[5]
+	key := string(uint64Bytes(v.key))
+	trace, err := NewMyDES("").TraceBlock(uint64Bytes(v.plain), key, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trace.Output != v.cipher {
+		t.Fatalf("Output = %016x, ожидалось %016x", trace.Output, v.cipher)
+	}
+	if standardVariant.fp.apply(trace.PreOutput) != trace.Output {
+		t.Fatal("IP^-1(PreOutput) != Output")
+	}
+
+	subKeys, rounds := legacyRoundStates(v.key, v.plain)
+	if len(trace.Rounds) != 16 {
+		t.Fatalf("записано %d раундов", len(trace.Rounds))
+	}
+	for i, r := range trace.Rounds {
+		if r.SubKey != subKeys[i] {
+			t.Errorf("раунд %d: подключ %012x, ожидалось %012x", i+1, r.SubKey, subKeys[i])
+		}
+		if got := uint64(r.NextLeft)<<32 | uint64(r.NextRight); got != rounds[i] {
+			t.Errorf("раунд %d: L||R = %016x, ожидалось %016x", i+1, got, rounds[i])
+		}
+
+		// Выходы S-блоков, собранные вместе, должны давать вход перестановки P
+		var sBoxes uint32
+		for _, out := range r.SBoxOut {
+			sBoxes = sBoxes<<4 | uint32(out)
+		}
+		if standardVariant.p.apply(uint64(sBoxes)) != uint64(r.PBox) {
+			t.Errorf("раунд %d: P(S) != PBox", i+1)
+		}
+	}
+}
+
+// TestTraceTripleDES проверяет трассировку 48 раундов Triple DES при расшифровке
+func TestTraceTripleDES(t *testing.T) {
+	key := "0123456789abcdefFEDCBA98"
+	reference, err := des.NewTripleDESCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipherText := make([]byte, BlockSize)
+	reference.Encrypt(cipherText, []byte("Trace 3D"))
+
+	trace, err := NewMyDES("", WithAlgorithm(AlgorithmTripleDES)).TraceBlock(cipherText, key, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Rounds) != 48 || trace.Rounds[47].Pass != 3 || trace.Rounds[47].Round != 16 {
+		t.Fatalf("записано %d раундов", len(trace.Rounds))
+	}
+	if trace.Output != binary.BigEndian.Uint64([]byte("Trace 3D")) {
+		t.Fatalf("Output = %016x", trace.Output)
+	}
+
+	// Внутри прохода выход раунда - вход следующего
+	for i := 1; i < len(trace.Rounds); i++ {
+		prev, r := trace.Rounds[i-1], trace.Rounds[i]
+		if r.Round > 1 && (r.Left != prev.NextLeft || r.Right != prev.NextRight) {
+			t.Fatalf("проход %d, раунд %d: вход не совпадает с выходом предыдущего раунда", r.Pass, r.Round)
+		}
+	}
+}
+
+// TestTraceBlockErrors проверяет отказ от неполного блока и неверного ключа
+func TestTraceBlockErrors(t *testing.T) {
+	if _, err := NewMyDES("").TraceBlock([]byte("short"), "Secret_8", false); err == nil {
+		t.Error("принят блок из 5 байт")
+	}
+	if _, err := NewMyDES("").TraceBlock([]byte("12345678"), "key", false); err == nil {
+		t.Error("принят ключ из 3 байт")
+	}
+}
@@ -0,0 +1,86 @@
+package myDes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrKeyingOption возвращается, если ключи Triple DES совпадают и шифр вырождается в одинарный DES
+var ErrKeyingOption = errors.New("myDes: недопустимый вариант ключей Triple DES: соседние ключи совпадают")
+
+// TripleDES представляет собой алгоритм Triple DES в режиме EDE (шифрование-расшифровка-шифрование)
+type TripleDES struct {
+	first, second, third *MyDES // Экземпляры DES для ключей K1, K2 и K3
+}
+
+// NewTripleDES создает Triple DES с ключом из 16 байт (EDE2: K1, K2, K1) или 24 байт (EDE3: K1, K2, K3).
+// Ключи, при которых K1 = K2 или K2 = K3, отклоняются, так как с ними шифр сводится к одинарному DES
+func NewTripleDES(key []byte) (*TripleDES, error) {
+	var k1, k2, k3 []byte
+	switch len(key) {
+	case 2 * BlockSize:
+		k1, k2, k3 = key[:8], key[8:16], key[:8]
+	case 3 * BlockSize:
+		k1, k2, k3 = key[:8], key[8:16], key[16:24]
+	default:
+		return nil, KeySizeError(len(key))
+	}
+
+	// Проверка варианта ключей: промежуточная расшифровка не должна отменять соседнее шифрование
+	if string(k1) == string(k2) || string(k2) == string(k3) {
+		return nil, ErrKeyingOption
+	}
+
+	return newTripleDES(k1, k2, k3, nil, 0), nil
+}
+
+// newTripleDES создает Triple DES из трех ключей с таблицами варианта variant и числом раундов rounds
+// в каждом проходе без проверки варианта ключей
+func newTripleDES(k1, k2, k3 []byte, variant *Variant, rounds int) *TripleDES {
+	return &TripleDES{
+		first:  newDESBlock(string(k1), variant, rounds),
+		second: newDESBlock(string(k2), variant, rounds),
+		third:  newDESBlock(string(k3), variant, rounds),
+	}
+}
+
+// BlockSize возвращает размер блока шифра
+func (t *TripleDES) BlockSize() int {
+	return BlockSize
+}
+
+// Encrypt шифрует первый блок из src по схеме E(K3, D(K2, E(K1, x)))
+func (t *TripleDES) Encrypt(dst, src []byte) {
+	t.cryptBlock(dst, src, false, nil)
+}
+
+// Decrypt расшифровывает первый блок из src по схеме D(K1, E(K2, D(K3, x)))
+func (t *TripleDES) Decrypt(dst, src []byte) {
+	t.cryptBlock(dst, src, true, nil)
+}
+
+// cryptBlock выполняет три прохода сети Фейстеля между одной начальной и одной конечной перестановкой.
+// Промежуточные перестановки IP^-1 и IP взаимно сокращаются, поэтому их можно не выполнять.
+// hook, если он задан, вызывается перед каждым из 48 раундов
+func (t *TripleDES) cryptBlock(dst, src []byte, isDecode bool, hook roundHook) {
+	if len(src) < BlockSize {
+		panic("myDes: входные данные меньше блока")
+	}
+	if len(dst) < BlockSize {
+		panic("myDes: выходной буфер меньше блока")
+	}
+
+	first, third := t.first, t.third
+	if isDecode {
+		first, third = third, first
+	}
+
+	block := first.initReplaceBlock(binary.BigEndian.Uint64(src))
+	block = first.iteration(block, first.schedule, isDecode, hook)
+	block = t.second.iteration(block, t.second.schedule, !isDecode, hook)
+	block = third.iteration(block, third.schedule, isDecode, hook)
+	binary.BigEndian.PutUint64(dst, third.endReplaceBlock(block))
+}
+
+var _ cipher.Block = (*TripleDES)(nil)
@@ -0,0 +1,113 @@
+package myDes
+
+import (
+	"bytes"
+	"crypto/des"
+	"errors"
+	"testing"
+)
+
+// TestTripleDESMatchesStdlib сверяет TripleDES с crypto/des.NewTripleDESCipher для EDE2 и EDE3
+func TestTripleDESMatchesStdlib(t *testing.T) {
+	keys := [][]byte{
+		[]byte("0123456789abcdef"),
+		[]byte("0123456789abcdefFEDCBA98"),
+		[]byte("Super_Secret_key"),
+	}
+	plain := []byte("Открытый текст для Triple DES!!!")
+
+	for _, key := range keys {
+		block, err := NewTripleDES(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// crypto/des принимает только 24-байтовый ключ, поэтому EDE2 разворачивается в K1, K2, K1
+		stdKey := key
+		if len(key) == 16 {
+			stdKey = append(append([]byte(nil), key...), key[:8]...)
+		}
+		reference, err := des.NewTripleDESCipher(stdKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < len(plain); i += BlockSize {
+			got, want := make([]byte, BlockSize), make([]byte, BlockSize)
+			block.Encrypt(got, plain[i:])
+			reference.Encrypt(want, plain[i:])
+			if !bytes.Equal(got, want) {
+				t.Fatalf("ключ %q, блок %d: получено %x, ожидалось %x", key, i/BlockSize, got, want)
+			}
+
+			block.Decrypt(got, want)
+			if !bytes.Equal(got, plain[i:i+BlockSize]) {
+				t.Fatalf("ключ %q, блок %d: расшифровано %x", key, i/BlockSize, got)
+			}
+		}
+	}
+}
+
+// TestTripleDESKeyingOptions проверяет отказ от ключей неверной длины и вырожденных вариантов ключей
+func TestTripleDESKeyingOptions(t *testing.T) {
+	tests := []struct {
+		key  string
+		want error
+	}{
+		{"12345678", KeySizeError(8)},
+		{"1234567812345678", ErrKeyingOption},
+		{"12345678abcdefghabcdefgh", ErrKeyingOption},
+		{"1234567812345678abcdefgh", ErrKeyingOption},
+		{"12345678abcdefgh12345678", nil},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewTripleDES([]byte(tt.key)); !errors.Is(err, tt.want) {
+			t.Errorf("NewTripleDES(%q) = %v, ожидалось %v", tt.key, err, tt.want)
+		}
+	}
+}
+
+// TestEncodeTripleDES проверяет шифрование строк через MyDES с выбранным Triple DES
+func TestEncodeTripleDES(t *testing.T) {
+	d := NewMyDES("01234567", WithAlgorithm(AlgorithmTripleDES))
+	if err := d.CheckKey("Super_Secret_key"); err != nil {
+		t.Fatal(err)
+	}
+
+	input := "Encrypt this file with Triple DES"
+	cipherText := mustEncode(t, d, input, "Super_Secret_key")
+	if cipherText == mustEncode(t, NewMyDES("01234567"), input, "Super_Secret_key") {
+		t.Fatal("шифротексты DES и Triple DES совпадают")
+	}
+	got, err := d.Decode([]byte(cipherText), "Super_Secret_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != input {
+		t.Fatalf("Decode = %q, ожидалось %q", got, input)
+	}
+}
+
+// TestCheckKey проверяет требования к длине ключа для каждого алгоритма
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		algorithm Algorithm
+		key       string
+		valid     bool
+	}{
+		{AlgorithmDES, "12345678", true},
+		{AlgorithmDES, "key", false},
+		{AlgorithmDES, "Super_Secret_key", false},
+		{AlgorithmTripleDES, "Super_Secret_key", true},
+		{AlgorithmTripleDES, "0123456789abcdefFEDCBA98", true},
+		{AlgorithmTripleDES, "12345678", false},
+		{AlgorithmTripleDES, "1234567812345678", false},
+	}
+	for _, tt := range tests {
+		err := NewMyDES("", WithAlgorithm(tt.algorithm)).CheckKey(tt.key)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: CheckKey(%q) = %v", tt.algorithm, tt.key, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package myDes
+
+import (
+	"math/bits"
+	"strconv"
+)
+
+// KeyClass описывает стойкость ключа DES с точки зрения его расписания подключей
+type KeyClass int
+
+const (
+	KeyStrong       KeyClass = iota // Все 16 подключей различны
+	KeyPossiblyWeak                 // Только 4 различных подключа
+	KeySemiWeak                     // Только 2 различных подключа: у ключа есть парный, расшифровывающий его шифротекст
+	KeyWeak                         // Все подключи одинаковы: шифрование совпадает с расшифровкой
+)
+
+// String возвращает название класса ключа
+func (k KeyClass) String() string {
+	switch k {
+	case KeyStrong:
+		return "strong"
+	case KeyPossiblyWeak:
+		return "possibly weak"
+	case KeySemiWeak:
+		return "semi-weak"
+	case KeyWeak:
+		return "weak"
+	default:
+		return "unknown"
+	}
+}
+
+// Parity определяет, как проверка ключа обращается с битами четности (младшим битом каждого байта)
+type Parity int
+
+const (
+	ParityIgnore  Parity = iota // Биты четности не проверяются: DES их не использует
+	ParityEnforce               // Каждый байт ключа должен иметь нечетное число единичных битов
+	ParityFix                   // Биты четности исправляются в возвращаемой копии ключа (и в основном ключе Seal и Open, см. WithKeyValidation)
+)
+
+// WeakKeyError описывает слабый, полуслабый или возможно слабый ключ DES
+type WeakKeyError struct {
+	Part  int      // Номер 8-байтовой части ключа Triple DES, начиная с нуля (для DES - 0)
+	Class KeyClass // Класс ключа
+}
+
+func (e *WeakKeyError) Error() string {
+	reason := "только 4 различных подключа"
+	switch e.Class {
+	case KeyWeak:
+		reason = "все подключи одинаковы, повторное шифрование возвращает открытый текст"
+	case KeySemiWeak:
+		reason = "только 2 различных подключа, существует парный ключ, расшифровывающий шифротекст"
+	}
+	return "myDes: ключ " + strconv.Itoa(e.Part+1) + " - " + e.Class.String() + ": " + reason
+}
+
+// ParityError описывает байт ключа с четным числом единичных битов
+type ParityError struct {
+	Byte int // Номер байта ключа, начиная с нуля
+}
+
+func (e *ParityError) Error() string {
+	return "myDes: байт " + strconv.Itoa(e.Byte) + " ключа не имеет нечетной четности"
+}
+
+// ClassifyKey определяет класс 8-байтового ключа DES по числу различных подключей.
+// Биты четности на результат не влияют, так как отбрасываются перестановкой PC-1.
+// Помимо 4 слабых и 12 полуслабых ключей, к возможно слабым относятся все ключи, половины C и D
+// которых после PC-1 повторяются с периодом 4 бита; обычно приводимые 48 возможно слабых ключей - часть из них
+func ClassifyKey(key []byte) KeyClass {
+	return (*Variant)(nil).ClassifyKey(key)
+}
+
+// ClassifyKey определяет класс ключа по расписанию подключей варианта v (nil - стандарт):
+// PC-1, PC-2 и сдвиги варианта могут сделать слабыми другие ключи, чем в стандарте
+func (v *Variant) ClassifyKey(key []byte) KeyClass {
+	schedule := (&MyDES{variant: v}).keySelectionReplacement(string(key))
+
+	distinct := make(map[uint64]struct{}, len(schedule))
+	for _, childKey := range schedule {
+		distinct[childKey] = struct{}{}
+	}
+
+	switch len(distinct) {
+	case 1:
+		return KeyWeak
+	case 2:
+		return KeySemiWeak
+	case 4:
+		return KeyPossiblyWeak
+	default:
+		return KeyStrong
+	}
+}
+
+// FixParity возвращает копию ключа, в которой младший бит каждого байта выставлен так,
+// чтобы число единичных битов в байте было нечетным
+func FixParity(key []byte) []byte {
+	fixed := make([]byte, len(key))
+	for i, b := range key {
+		b &^= 1
+		if bits.OnesCount8(b)%2 == 0 {
+			b |= 1
+		}
+		fixed[i] = b
+	}
+	return fixed
+}
+
+// ValidateKey проверяет ключ DES (8 байт) или Triple DES (16 или 24 байта): каждая 8-байтовая часть
+// не должна быть слабой, полуслабой или возможно слабой. Четность проверяется или исправляется
+// согласно parity. Возвращает ключ (при ParityFix - исправленную копию) или
+// *WeakKeyError, *ParityError, KeySizeError
+func ValidateKey(key []byte, parity Parity) ([]byte, error) {
+	return (*Variant)(nil).ValidateKey(key, parity)
+}
+
+// ValidateKey выполняет ValidateKey, определяя слабые ключи по расписанию подключей варианта v (nil - стандарт)
+func (v *Variant) ValidateKey(key []byte, parity Parity) ([]byte, error) {
+	if len(key) != BlockSize && len(key) != 2*BlockSize && len(key) != 3*BlockSize {
+		return nil, KeySizeError(len(key))
+	}
+
+	switch parity {
+	case ParityEnforce:
+		for i, b := range key {
+			if bits.OnesCount8(b)%2 == 0 {
+				return nil, &ParityError{Byte: i}
+			}
+		}
+	case ParityFix:
+		key = FixParity(key)
+	}
+
+	for part := 0; part < len(key)/BlockSize; part++ {
+		if class := v.ClassifyKey(key[part*BlockSize : (part+1)*BlockSize]); class != KeyStrong {
+			return nil, &WeakKeyError{Part: part, Class: class}
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,171 @@
+package myDes
+
+import (
+	"errors"
+	"testing"
+)
+
+// weakKeys и semiWeakKeys - слабые и полуслабые ключи DES из FIPS 74
+var (
+	weakKeys = []uint64{
+		0x0101010101010101, 0xFEFEFEFEFEFEFEFE, 0xE0E0E0E0F1F1F1F1, 0x1F1F1F1F0E0E0E0E,
+	}
+	semiWeakKeys = []uint64{
+		0x011F011F010E010E, 0x1F011F010E010E01,
+		0x01E001E001F101F1, 0xE001E001F101F101,
+		0x01FE01FE01FE01FE, 0xFE01FE01FE01FE01,
+		0x1FE01FE00EF10EF1, 0xE01FE01FF10EF10E,
+		0x1FFE1FFE0EFE0EFE, 0xFE1FFE1FFE0EFE0E,
+		0xE0FEE0FEF1FEF1FE, 0xFEE0FEE0FEF1FEF1,
+	}
+)
+
+// TestClassifyKey проверяет классы известных ключей, в том числе с неверной четностью
+func TestClassifyKey(t *testing.T) {
+	tests := []struct {
+		keys []uint64
+		want KeyClass
+	}{
+		{weakKeys, KeyWeak},
+		{semiWeakKeys, KeySemiWeak},
+		{[]uint64{0x01011F1F01010E0E, 0x1F1F01010E0E0101, 0xE0E01F1FF1F10E0E, 0x0101E0E00101F1F1}, KeyPossiblyWeak},
+		{[]uint64{0x0123456789ABCDEF, 0x133457799BBCDFF1}, KeyStrong},
+	}
+	for _, tt := range tests {
+		for _, key := range tt.keys {
+			if got := ClassifyKey(uint64Bytes(key)); got != tt.want {
+				t.Errorf("ClassifyKey(%016X) = %s, ожидалось %s", key, got, tt.want)
+			}
+			// Биты четности отбрасываются PC-1 и не влияют на класс
+			if got := ClassifyKey(uint64Bytes(key ^ 0x0101010101010101)); got != tt.want {
+				t.Errorf("ClassifyKey(%016X) с инвертированной четностью = %s", key, got)
+			}
+		}
+	}
+}
+
+// TestSemiWeakPairs проверяет, что каждый полуслабый ключ расшифровывает шифротекст парного
+func TestSemiWeakPairs(t *testing.T) {
+	plain := uint64Bytes(0x0123456789ABCDEF)
+	for i := 0; i < len(semiWeakKeys); i += 2 {
+		first, _ := NewCipher(uint64Bytes(semiWeakKeys[i]))
+		second, _ := NewCipher(uint64Bytes(semiWeakKeys[i+1]))
+
+		buf := make([]byte, BlockSize)
+		first.Encrypt(buf, plain)
+		second.Encrypt(buf, buf)
+		if string(buf) != string(plain) {
+			t.Errorf("пара %016X/%016X: E(K2, E(K1, x)) != x", semiWeakKeys[i], semiWeakKeys[i+1])
+		}
+	}
+}
+
+// TestValidateKey проверяет отказ от слабых ключей и обработку четности
+func TestValidateKey(t *testing.T) {
+	var (
+		weakErr   *WeakKeyError
+		parityErr *ParityError
+	)
+
+	if _, err := ValidateKey(uint64Bytes(weakKeys[0]), ParityIgnore); !errors.As(err, &weakErr) || weakErr.Class != KeyWeak {
+		t.Errorf("слабый ключ: %v", err)
+	}
+
+	// Во второй части ключа Triple DES - полуслабый ключ
+	tripleKey := append(uint64Bytes(0x0123456789ABCDEF), uint64Bytes(semiWeakKeys[0])...)
+	if _, err := ValidateKey(tripleKey, ParityIgnore); !errors.As(err, &weakErr) || weakErr.Part != 1 {
+		t.Errorf("Triple DES с полуслабой частью: %v", err)
+	}
+
+	// "Secret_8": у байтов 'S' и 'e' четное число единиц
+	if _, err := ValidateKey([]byte("Secret_8"), ParityEnforce); !errors.As(err, &parityErr) || parityErr.Byte != 0 {
+		t.Errorf("неверная четность: %v", err)
+	}
+	fixed, err := ValidateKey([]byte("Secret_8"), ParityFix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateKey(fixed, ParityEnforce); err != nil {
+		t.Errorf("исправленный ключ %x: %v", fixed, err)
+	}
+	if ClassifyKey(fixed) != ClassifyKey([]byte("Secret_8")) {
+		t.Error("исправление четности изменило подключи")
+	}
+
+	if _, err := ValidateKey([]byte("short"), ParityIgnore); err == nil {
+		t.Error("ключ из 5 байт принят")
+	}
+}
+
+// TestKeyValidationOption проверяет отказ Seal и Open от слабых ключей при WithKeyValidation
+func TestKeyValidationOption(t *testing.T) {
+	weak := string(uint64Bytes(weakKeys[1]))
+	sealed, err := NewMyDES("").Seal([]byte("text"), weak)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var weakErr *WeakKeyError
+	d := NewMyDES("", WithKeyValidation(ParityIgnore))
+	if _, err := d.Seal([]byte("text"), weak); !errors.As(err, &weakErr) {
+		t.Errorf("Seal со слабым ключом: %v", err)
+	}
+	if _, err := d.Open(sealed, weak); !errors.As(err, &weakErr) {
+		t.Errorf("Open со слабым ключом: %v", err)
+	}
+
+	// Ключ, выработанный из пароля, проверяется на слабость, но не на четность
+	kdf := NewMyDES("", WithKDF(PBKDF2(1000)), WithKeyValidation(ParityEnforce))
+	sealed, err = kdf.Seal([]byte("text"), "пароль")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kdf.Open(sealed, "пароль"); err != nil {
+		t.Fatal(err)
+	}
+
+	// При ParityFix контейнер шифруется ключом с исправленной четностью
+	fix := NewMyDES("", WithMAC(), WithKeyValidation(ParityFix))
+	sealed, err = fix.Seal([]byte("text"), "Secret_8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := string(FixParity([]byte("Secret_8")))
+	if plain, err := NewMyDES("", WithMAC()).Open(sealed, fixed); err != nil || string(plain) != "text" {
+		t.Errorf("Open исправленным ключом: %q, %v", plain, err)
+	}
+	if plain, err := fix.Open(sealed, "Secret_8"); err != nil || string(plain) != "text" {
+		t.Errorf("Open с ParityFix: %q, %v", plain, err)
+	}
+}
+
+// TestVariantKeyValidation проверяет, что слабые ключи определяются по расписанию подключей варианта:
+// после обмена двух позиций PC-1 между половинами C и D слабыми становятся другие ключи
+func TestVariantKeyValidation(t *testing.T) {
+	tables := StandardTables()
+	tables.Name = "pc1-swapped"
+	tables.PC1[0], tables.PC1[28] = tables.PC1[28], tables.PC1[0]
+	variant, err := NewVariant(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Биты 57 и 63 ключа меняются местами в C и D: у этого ключа половины C и D варианта постоянны
+	variantWeak := uint64Bytes(0x1F1F1F1F0E0E0E8C)
+	standardWeak := uint64Bytes(weakKeys[3])
+	if got := variant.ClassifyKey(variantWeak); got != KeyWeak {
+		t.Errorf("ClassifyKey варианта = %s, ожидалось weak", got)
+	}
+	if got := variant.ClassifyKey(standardWeak); got != KeyStrong {
+		t.Errorf("слабый ключ стандарта в варианте: %s", got)
+	}
+
+	var weakErr *WeakKeyError
+	d := NewMyDES("", WithVariant(variant), WithKeyValidation(ParityEnforce))
+	if _, err := d.Seal([]byte("text"), string(variantWeak)); !errors.As(err, &weakErr) {
+		t.Errorf("Seal со слабым ключом варианта: %v", err)
+	}
+	if _, err := d.Seal([]byte("text"), string(standardWeak)); err != nil {
+		t.Errorf("Seal с ключом, слабым только в стандарте: %v", err)
+	}
+}
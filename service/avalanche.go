@@ -0,0 +1,163 @@
+package service
+
+import (
+	"IB3/myDes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Размеры графика лавинного эффекта в пикселях
+const (
+	chartWidth  = 640
+	chartHeight = 320
+	chartMargin = 40
+)
+
+// chartTick - подпись на оси графика
+type chartTick struct {
+	Position float64 // Координата на оси
+	Label    string
+}
+
+// avalancheRoundView - средние значения для одного раунда в таблице под графиком
+type avalancheRoundView struct {
+	Round     int
+	Plaintext string
+	Key       string
+}
+
+// avalanchePage - данные шаблона templates/avalanche.html
+type avalanchePage struct {
+	Form      url.Values
+	Error     string
+	CSV       bool // Отчет построен, и его можно скачать в CSV той же формой
+	Width     int
+	Height    int
+	Left      float64 // Границы области построения
+	Right     float64
+	Top       float64
+	Bottom    float64
+	Half      float64 // Координата линии 32 бит - ожидаемого среднего для хорошего шифра
+	Plaintext string  // Точки ломаной для инвертирования бит открытого текста
+	Key       string  // Точки ломаной для инвертирования бит ключа
+	XTicks    []chartTick
+	YTicks    []chartTick
+	Rounds    []avalancheRoundView
+}
+
+// avalancheFromRequest выполняет лавинный анализ блока и ключа из формы
+func avalancheFromRequest(r *http.Request) (*myDes.AvalancheReport, error) {
+	key, err := keyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := algorithmFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	block, err := blockFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := variantFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	rounds, err := roundsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	d := myDes.NewMyDES(legacyIV, myDes.WithAlgorithm(algorithm), myDes.WithVariant(variant), myDes.WithRounds(rounds))
+	return d.Avalanche(block, key)
+}
+
+// chartPoints переводит средние значения по раундам в точки ломаной на графике
+func (p *avalanchePage) chartPoints(mean []float64) string {
+	points := make([]string, len(mean))
+	for i, v := range mean {
+		points[i] = fmt.Sprintf("%.1f,%.1f", p.x(i+1, len(mean)), p.y(v))
+	}
+	return strings.Join(points, " ")
+}
+
+// x возвращает координату раунда round из rounds
+func (p *avalanchePage) x(round, rounds int) float64 {
+	if rounds == 1 {
+		return p.Left
+	}
+	return p.Left + (p.Right-p.Left)*float64(round-1)/float64(rounds-1)
+}
+
+// y возвращает координату числа измененных бит (от 0 до 64)
+func (p *avalanchePage) y(changed float64) float64 {
+	return p.Bottom - (p.Bottom-p.Top)*changed/64
+}
+
+// fillChart заполняет график и таблицу средних значений по отчету
+func (p *avalanchePage) fillChart(report *myDes.AvalancheReport) {
+	p.Width, p.Height = chartWidth, chartHeight
+	p.Left, p.Right = chartMargin, chartWidth-chartMargin/2
+	p.Top, p.Bottom = chartMargin/2, chartHeight-chartMargin
+	p.Half = p.y(32)
+
+	plaintext, key := report.PlaintextMean(), report.KeyMean()
+	p.Plaintext = p.chartPoints(plaintext)
+	p.Key = p.chartPoints(key)
+
+	for bits := 0; bits <= 64; bits += 16 {
+		p.YTicks = append(p.YTicks, chartTick{Position: p.y(float64(bits)), Label: fmt.Sprint(bits)})
+	}
+	// Для Triple DES подписывается каждый четвертый из 48 раундов
+	step := 1
+	if report.Rounds > 16 {
+		step = 4
+	}
+	for round := 1; round <= report.Rounds; round++ {
+		if round%step == 0 || round == 1 {
+			p.XTicks = append(p.XTicks, chartTick{Position: p.x(round, report.Rounds), Label: fmt.Sprint(round)})
+		}
+		p.Rounds = append(p.Rounds, avalancheRoundView{
+			Round:     round,
+			Plaintext: fmt.Sprintf("%.2f", plaintext[round-1]),
+			Key:       fmt.Sprintf("%.2f", key[round-1]),
+		})
+	}
+}
+
+// Avalanche показывает лавинный эффект: для каждого раунда - среднее число бит, изменившихся
+// после инвертирования одного бита открытого текста или ключа. С полем format=csv отчет
+// по каждому инвертированному биту скачивается в виде CSV. Форма отправляется методом POST
+func (s *Service) Avalanche(w http.ResponseWriter, r *http.Request) {
+	if err := postFormOnly(r); err != nil {
+		renderError(w, err)
+		return
+	}
+	if r.FormValue("format") == "csv" {
+		report, err := avalancheFromRequest(r)
+		if err != nil {
+			renderError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="avalanche.csv"`)
+		if err := report.WriteCSV(w); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	page := avalanchePage{Form: r.Form}
+	status := http.StatusOK
+	if r.FormValue("key") != "" {
+		if report, err := avalancheFromRequest(r); err != nil {
+			status, page.Error = describeError(err)
+		} else {
+			page.fillChart(report)
+			page.CSV = true
+		}
+	}
+	renderPage(w, "avalanche.html", status, page)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"IB3/linear"
+	"IB3/myDes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+// formError - ошибка в поле формы: неизвестное значение списка, неверное число и т.п.
+// Ее текст показывается пользователю как есть
+type formError struct {
+	message string
+}
+
+func (e *formError) Error() string {
+	return e.message
+}
+
+// formErrorf создает formError с сообщением по формату
+func formErrorf(format string, args ...any) error {
+	return &formError{message: fmt.Sprintf(format, args...)}
+}
+
+var (
+	// errUpload возвращается, если из формы не удалось прочитать файл
+	errUpload = &formError{message: "Error uploading file"}
+	// errExperimentRunning возвращается, пока идет другой эксперимент линейного криптоанализа
+	errExperimentRunning = errors.New("another linear experiment is running")
+)
+
+// errorResponse - HTTP-статус и сообщение для ошибок одного вида
+type errorResponse struct {
+	match   func(err error) bool
+	status  int
+	message func(err error) string
+}
+
+// matchAs возвращает функцию, которая проверяет, есть ли в цепочке ошибок ошибка типа T
+func matchAs[T error]() func(err error) bool {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// matchIs возвращает функцию, которая проверяет, есть ли в цепочке ошибок одна из targets
+func matchIs(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// text возвращает сообщение, не зависящее от ошибки
+func text(message string) func(error) string {
+	return func(error) string { return message }
+}
+
+// detailed возвращает сообщение с пояснением prefix и текстом ошибки
+func detailed(prefix string) func(error) string {
+	return func(err error) string { return prefix + err.Error() }
+}
+
+// errorResponses описывает ошибки, о которых сообщается пользователю. Вид ошибки определяется первой
+// подходящей строкой, поэтому более конкретные ошибки идут раньше. Остальные ошибки считаются внутренними:
+// они пишутся в журнал, а пользователь получает статус 500 без подробностей
+var errorResponses = []errorResponse{
+	{matchAs[*formError](), http.StatusBadRequest, func(err error) string { return err.Error() }},
+	{matchAs[*myDes.IntegrityError](), http.StatusBadRequest,
+		text("Integrity check failed: the file was modified or truncated, or the key is wrong")},
+	{matchIs(myDes.ErrNotContainer), http.StatusBadRequest,
+		text("The file is not an authenticated container produced by /home/shifr. Old hex files have no integrity check, " +
+			"enable the option for old hex files to decrypt them anyway")},
+	{matchAs[*myDes.MismatchError](), http.StatusBadRequest,
+		detailed("The file was encrypted with different DES tables or a different number of rounds, select the same ones: ")},
+	{matchAs[*myDes.WeakKeyError](), http.StatusBadRequest, func(err error) string {
+		var weakKeyErr *myDes.WeakKeyError
+		errors.As(err, &weakKeyErr)
+		return weakKeyMessage(weakKeyErr)
+	}},
+	{matchAs[*myDes.ParityError](), http.StatusBadRequest, func(err error) string {
+		var parityErr *myDes.ParityError
+		errors.As(err, &parityErr)
+		return fmt.Sprintf("The key was refused: byte %d does not have odd parity. "+
+			"Each key byte must contain an odd number of 1 bits, or turn off the parity check", parityErr.Byte)
+	}},
+	{matchAs[myDes.RoundsError](), http.StatusBadRequest, func(err error) string {
+		var roundsErr myDes.RoundsError
+		errors.As(err, &roundsErr)
+		return fmt.Sprintf("The number of rounds must be between 1 and %d, got %d", myDes.MaxRounds, int(roundsErr))
+	}},
+	{matchAs[*myDes.TablesError](), http.StatusBadRequest, detailed("The selected DES variant has invalid tables: ")},
+	{matchAs[*myDes.HexError](), http.StatusBadRequest, detailed("The file is not a ciphertext produced by /home/shifr: ")},
+	{matchAs[*myDes.BlockLengthError](), http.StatusBadRequest, detailed("The ciphertext has a block of the wrong length: ")},
+	{matchAs[*myDes.PaddingError](), http.StatusBadRequest, detailed("Decryption failed, check the key, mode, padding and DES tables: ")},
+	{matchAs[*myDes.ContainerError](), http.StatusBadRequest, detailed("The encrypted file is damaged or was produced by a newer version: ")},
+	{matchAs[myDes.KeySizeError](), http.StatusBadRequest, detailed("Invalid key: ")},
+	{matchIs(myDes.ErrKeyingOption, myDes.ErrEmptyPassphrase, myDes.ErrPassphraseNUL), http.StatusBadRequest, detailed("Invalid key: ")},
+	{matchIs(linear.ErrNoApproximation, linear.ErrGuessBoxes, linear.ErrExperimentRounds, linear.ErrApproximationRounds),
+		http.StatusBadRequest, detailed("The experiment cannot be run for these DES tables: ")},
+	{matchIs(errExperimentRunning), http.StatusServiceUnavailable,
+		text("Another experiment is running, try again in a few seconds")},
+}
+
+// describeError возвращает HTTP-статус и понятное пользователю сообщение для ошибки
+func describeError(err error) (int, string) {
+	for _, response := range errorResponses {
+		if response.match(err) {
+			return response.status, response.message(err)
+		}
+	}
+	log.Println(err)
+	return http.StatusInternalServerError, "Error processing request"
+}
+
+// renderError отвечает на запрос текстом ошибки с подходящим статусом
+func renderError(w http.ResponseWriter, err error) {
+	status, message := describeError(err)
+	http.Error(w, message, status)
+}
+
+// renderPage выполняет шаблон templates/name с данными page и отвечает статусом status
+func renderPage(w http.ResponseWriter, name string, status int, page any) {
+	tmpl, err := template.ParseFiles("templates/" + name)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Error loading page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := tmpl.Execute(w, page); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"IB3/myDes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// bitValue - значение в шестнадцатеричном и двоичном виде
+type bitValue struct {
+	Hex string `json:"hex"`
+	Bin string `json:"bin"`
+}
+
+// newBitValue форматирует значение из bits бит; двоичный вид разбивается на группы по group бит
+func newBitValue(v uint64, bits, group int) bitValue {
+	return bitValue{Hex: hexBits(v, bits), Bin: binBits(v, bits, group)}
+}
+
+// keyRoundView - состояние расписания ключей после одного раунда
+type keyRoundView struct {
+	Round  int      `json:"round"`
+	Shift  int      `json:"shift"`
+	C      bitValue `json:"c"`
+	D      bitValue `json:"d"`
+	SubKey bitValue `json:"subKey"`
+}
+
+// keyScheduleView - расписание ключей одной 8-байтовой части ключа
+type keyScheduleView struct {
+	Part   int            `json:"part"`
+	Key    bitValue       `json:"key"`
+	PC1    bitValue       `json:"pc1"`
+	C0     bitValue       `json:"c0"`
+	D0     bitValue       `json:"d0"`
+	Rounds []keyRoundView `json:"rounds"`
+}
+
+// keySchedulePage - данные шаблона templates/keyschedule.html
+type keySchedulePage struct {
+	Form  url.Values
+	Error string
+	Parts []keyScheduleView
+}
+
+// keyScheduleFromRequest строит расписание для каждой 8-байтовой части ключа из формы:
+// одной для DES и двух или трех для Triple DES. PC-1, PC-2 и сдвиги берутся из выбранных таблиц DES
+func keyScheduleFromRequest(r *http.Request) ([]keyScheduleView, error) {
+	key, err := keyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := variantFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != myDes.BlockSize && len(key) != 2*myDes.BlockSize && len(key) != 3*myDes.BlockSize {
+		return nil, myDes.KeySizeError(len(key))
+	}
+
+	var parts []keyScheduleView
+	for part := 0; part < len(key)/myDes.BlockSize; part++ {
+		trace, err := variant.TraceKeySchedule([]byte(key[part*myDes.BlockSize : (part+1)*myDes.BlockSize]))
+		if err != nil {
+			return nil, err
+		}
+
+		// Ключ выводится по байтам, PC-1 и половины - группами по 7 бит, подключи - по 6 бит на S-блок
+		view := keyScheduleView{
+			Part: part + 1,
+			Key:  newBitValue(trace.Key, 64, 8),
+			PC1:  newBitValue(trace.PC1, 56, 7),
+			C0:   newBitValue(uint64(trace.C0), 28, 7),
+			D0:   newBitValue(uint64(trace.D0), 28, 7),
+		}
+		for _, round := range trace.Rounds {
+			view.Rounds = append(view.Rounds, keyRoundView{
+				Round:  round.Round,
+				Shift:  round.Shift,
+				C:      newBitValue(uint64(round.C), 28, 7),
+				D:      newBitValue(uint64(round.D), 28, 7),
+				SubKey: newBitValue(round.SubKey, 48, 6),
+			})
+		}
+		parts = append(parts, view)
+	}
+	return parts, nil
+}
+
+// KeySchedule показывает расписание ключей: результат PC-1, половины C и D после каждого сдвига
+// и 16 подключей после PC-2. С полем format=json те же данные возвращаются в виде JSON.
+// Ключ, как и на других страницах исследования шифра, принимается только в теле запроса POST
+func (s *Service) KeySchedule(w http.ResponseWriter, r *http.Request) {
+	if err := postFormOnly(r); err != nil {
+		renderError(w, err)
+		return
+	}
+	if r.FormValue("format") == "json" {
+		parts, err := keyScheduleFromRequest(r)
+		if err != nil {
+			renderError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string][]keyScheduleView{"parts": parts}); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	page := keySchedulePage{Form: r.Form}
+	status := http.StatusOK
+	if r.FormValue("key") != "" {
+		var err error
+		if page.Parts, err = keyScheduleFromRequest(r); err != nil {
+			status, page.Error = describeError(err)
+		}
+	}
+	renderPage(w, "keyschedule.html", status, page)
+}
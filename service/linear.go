@@ -0,0 +1,325 @@
+package service
+
+import (
+	"IB3/linear"
+	"IB3/myDes"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Параметры эксперимента по умолчанию и допустимые пределы: 2^21 известных текстов Мацуи хватает
+// для атаки на 8 раундов, и такой эксперимент занимает около секунды. На большее число раундов
+// атака с таким числом текстов не удается, поэтому страница их не предлагает
+const (
+	defaultLinearRounds  = 8
+	minLinearRounds      = 3
+	maxLinearRounds      = 8
+	defaultLinearSamples = 21
+	minLinearSamples     = 10
+	maxLinearSamples     = 21
+	linearCandidates     = 5 // Сколько лучших вариантов подключа показывать
+	strongLATValue       = 16
+)
+
+// latSummary - лучшее соотношение одного S-блока
+type latSummary struct {
+	Box   int
+	In    string
+	Out   string
+	Value int
+	Bias  string
+}
+
+// latCell - значение таблицы линейных аппроксимаций; Strong отмечает большие по модулю значения
+type latCell struct {
+	Value  int
+	Strong bool
+}
+
+// latRow - строка таблицы линейных аппроксимаций для одной маски входа
+type latRow struct {
+	In     string
+	Values []latCell
+}
+
+// approximationRound - аппроксимация одного раунда в таблице
+type approximationRound struct {
+	Round       int
+	Box         string
+	In          string
+	Out         string
+	Input       string
+	Output      string
+	Correlation string
+}
+
+// approximationView - линейная аппроксимация в шестнадцатеричном виде
+type approximationView struct {
+	Rounds  int
+	PlainL  string
+	PlainR  string
+	CipherL string
+	CipherR string
+	Bias    string
+	Texts   string // Оценка числа текстов 1/bias^2
+	Trail   []approximationRound
+}
+
+// candidateView - вариант бит подключа алгоритма 2
+type candidateView struct {
+	Rank      int
+	SubKey    string
+	Count     int
+	Deviation string
+	Correct   bool
+}
+
+// linearResult - результаты эксперимента
+type linearResult struct {
+	Rounds     int
+	Samples    int
+	Seed       int64
+	Key        string
+	Elapsed    string
+	Algorithm1 approximationView
+	Count      int
+	KeyBit     uint8
+	ActualBit  uint8
+
+	Algorithm2    approximationView
+	Guessed       int // Число перебиравшихся бит подключа
+	SubKey        string
+	ActualSubKey  string
+	Rank          int
+	KeyBit2       uint8
+	ActualBit2    uint8
+	Candidates    []candidateView
+	CandidatesAll int
+}
+
+// linearPage - данные шаблона templates/linear.html
+type linearPage struct {
+	Form    url.Values
+	Error   string
+	Box     int // S-блок, таблица которого показана (с 1)
+	Summary []latSummary
+	Out     []string // Заголовки столбцов таблицы
+	LAT     []latRow
+	Result  *linearResult
+}
+
+// linearAnalyzers хранит подготовленные анализаторы по идентификатору таблиц DES: таблицы аппроксимаций
+// и раундовые аппроксимации не зависят от ключа, и их не нужно строить заново для каждого запроса
+var linearAnalyzers = struct {
+	sync.Mutex
+	byID map[[8]byte]*linear.Analyzer
+}{byID: make(map[[8]byte]*linear.Analyzer)}
+
+// linearExperiment занят, пока идет эксперимент. Эксперименты выполняются по одному: каждый надолго занимает
+// процессор, а анализатор запоминает границы, найденные при поиске аппроксимаций
+var linearExperiment sync.Mutex
+
+// linearAnalyzer возвращает анализатор для таблиц variant, создавая его при первом запросе
+func linearAnalyzer(variant *myDes.Variant) *linear.Analyzer {
+	linearAnalyzers.Lock()
+	defer linearAnalyzers.Unlock()
+
+	id := variant.ID()
+	a, ok := linearAnalyzers.byID[id]
+	if !ok {
+		a = linear.NewAnalyzer(variant)
+		linearAnalyzers.byID[id] = a
+	}
+	return a
+}
+
+// linearIntFromRequest разбирает целый параметр формы в пределах [min, max] или возвращает значение по умолчанию
+func linearIntFromRequest(r *http.Request, name string, value, min, max int) (int, error) {
+	if text := strings.TrimSpace(r.FormValue(name)); text != "" {
+		var err error
+		if value, err = strconv.Atoi(text); err != nil {
+			return 0, formErrorf("%s must be a number: %q", name, text)
+		}
+	}
+	if value < min || value > max {
+		return 0, formErrorf("%s must be between %d and %d", name, min, max)
+	}
+	return value, nil
+}
+
+// formatBias возвращает смещение со степенью двойки, как в таблицах Мацуи
+func formatBias(bias float64) string {
+	return fmt.Sprintf("%+.4g (2^%.2f)", bias, math.Log2(math.Abs(bias)))
+}
+
+// newApproximationView переводит аппроксимацию в шестнадцатеричный вид
+func newApproximationView(ap *linear.Approximation) approximationView {
+	view := approximationView{
+		Rounds:  ap.Rounds,
+		PlainL:  hexBits(uint64(ap.PlainL), 32),
+		PlainR:  hexBits(uint64(ap.PlainR), 32),
+		CipherL: hexBits(uint64(ap.CipherL), 32),
+		CipherR: hexBits(uint64(ap.CipherR), 32),
+		Bias:    formatBias(ap.Bias),
+		Texts:   fmt.Sprintf("2^%.1f", -2*math.Log2(math.Abs(ap.Bias))),
+	}
+	for i, r := range ap.Trail {
+		row := approximationRound{Round: i + 1, Box: "-", Correlation: fmt.Sprintf("%+.4g", r.Correlation)}
+		if r.Box >= 0 {
+			row.Box = fmt.Sprintf("S%d", r.Box+1)
+			row.In = fmt.Sprintf("%02X", r.In)
+			row.Out = fmt.Sprintf("%X", r.Out)
+			row.Input = hexBits(uint64(r.Input), 32)
+			row.Output = hexBits(uint64(r.Output), 32)
+		}
+		view.Trail = append(view.Trail, row)
+	}
+	return view
+}
+
+// subKeyBits показывает биты подключа перебираемых S-блоков
+func subKeyBits(subKey uint64, boxes []int) string {
+	parts := make([]string, len(boxes))
+	for i, box := range boxes {
+		parts[i] = fmt.Sprintf("S%d: %06b", box+1, subKey>>(42-6*box)&0x3f)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fillLAT заполняет сводку по S-блокам и таблицу выбранного S-блока
+func (p *linearPage) fillLAT(a *linear.Analyzer) {
+	for box := 0; box < 8; box++ {
+		lat := a.LAT(box)
+		value, in, out := lat.Max()
+		p.Summary = append(p.Summary, latSummary{
+			Box:   box + 1,
+			In:    fmt.Sprintf("%02X", in),
+			Out:   fmt.Sprintf("%X", out),
+			Value: value,
+			Bias:  fmt.Sprintf("%+.4f", lat.Bias(in, out)),
+		})
+	}
+
+	lat := a.LAT(p.Box - 1)
+	for out := 0; out < 16; out++ {
+		p.Out = append(p.Out, fmt.Sprintf("%X", out))
+	}
+	for in := range lat {
+		row := latRow{In: fmt.Sprintf("%02X", in)}
+		for _, value := range lat[in] {
+			row.Values = append(row.Values, latCell{Value: value, Strong: in != 0 && (value >= strongLATValue || value <= -strongLATValue)})
+		}
+		p.LAT = append(p.LAT, row)
+	}
+}
+
+// runLinear проводит эксперимент с параметрами формы
+func runLinear(r *http.Request, a *linear.Analyzer) (*linearResult, error) {
+	key, err := keyFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	rounds, err := linearIntFromRequest(r, "rounds", defaultLinearRounds, minLinearRounds, maxLinearRounds)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := linearIntFromRequest(r, "samples", defaultLinearSamples, minLinearSamples, maxLinearSamples)
+	if err != nil {
+		return nil, err
+	}
+	seed := int64(1)
+	if text := strings.TrimSpace(r.FormValue("seed")); text != "" {
+		if seed, err = strconv.ParseInt(text, 10, 64); err != nil {
+			return nil, formErrorf("seed must be a number: %q", text)
+		}
+	}
+
+	start := time.Now()
+	e, err := a.Run([]byte(key), rounds, 1<<samples, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &linearResult{
+		Rounds:        rounds,
+		Samples:       e.Samples,
+		Seed:          seed,
+		Key:           fmt.Sprintf("%X", e.Key),
+		Elapsed:       time.Since(start).Round(time.Millisecond).String(),
+		Algorithm1:    newApproximationView(e.Algorithm1.Approximation),
+		Count:         e.Algorithm1.Count,
+		KeyBit:        e.Algorithm1.KeyBit,
+		ActualBit:     e.Algorithm1Actual,
+		Algorithm2:    newApproximationView(e.Algorithm2.Approximation),
+		Guessed:       6 * len(e.Algorithm2.Boxes),
+		SubKey:        subKeyBits(e.Algorithm2.SubKey, e.Algorithm2.Boxes),
+		ActualSubKey:  subKeyBits(e.SubKey, e.Algorithm2.Boxes),
+		Rank:          e.Rank,
+		KeyBit2:       e.Algorithm2.KeyBit,
+		ActualBit2:    e.Algorithm2Actual,
+		CandidatesAll: len(e.Algorithm2.Candidates),
+	}
+	for i, c := range e.Algorithm2.Candidates {
+		if i >= linearCandidates {
+			break
+		}
+		result.Candidates = append(result.Candidates, candidateView{
+			Rank:      i + 1,
+			SubKey:    subKeyBits(c.SubKey, e.Algorithm2.Boxes),
+			Count:     c.Count,
+			Deviation: fmt.Sprintf("%+.5f", float64(c.Count)/float64(e.Samples)-0.5),
+			Correct:   c.SubKey == e.SubKey,
+		})
+	}
+	return result, nil
+}
+
+// Linear показывает таблицы линейных аппроксимаций S-блоков и проводит эксперимент Мацуи:
+// алгоритмы 1 и 2 против DES с уменьшенным числом раундов на случайных известных открытых текстах.
+// Тексты порождаются из seed, поэтому тот же seed воспроизводит эксперимент. Форма с ключом отправляется
+// методом POST; пока идет один эксперимент, следующий отклоняется со статусом 503
+func (s *Service) Linear(w http.ResponseWriter, r *http.Request) {
+	if err := postFormOnly(r); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	page := linearPage{Form: r.Form}
+	status := http.StatusOK
+	box, err := linearIntFromRequest(r, "box", 5, 1, 8)
+	if err != nil {
+		status, page.Error = describeError(err)
+		box = 5
+	}
+	page.Box = box
+
+	variant, err := variantFromRequest(r)
+	if err != nil {
+		status, page.Error = describeError(err)
+	}
+	analyzer := linearAnalyzer(variant)
+	page.fillLAT(analyzer)
+
+	if status == http.StatusOK && r.FormValue("key") != "" {
+		if page.Result, err = runLinearExclusive(r, analyzer); err != nil {
+			status, page.Error = describeError(err)
+		}
+	}
+	renderPage(w, "linear.html", status, page)
+}
+
+// runLinearExclusive проводит эксперимент, если не идет другой, иначе возвращает errExperimentRunning
+func runLinearExclusive(r *http.Request, a *linear.Analyzer) (*linearResult, error) {
+	if !linearExperiment.TryLock() {
+		return nil, errExperimentRunning
+	}
+	defer linearExperiment.Unlock()
+	return runLinear(r, a)
+}
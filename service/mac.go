@@ -0,0 +1,109 @@
+package service
+
+import (
+	"IB3/mac"
+	"IB3/myDes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// macFromRequest создает код аутентичности, выбранный в форме: cbcmac и cmac используют выбранный алгоритм
+// (DES или Triple DES), retail всегда использует 16-байтовый ключ K || K'
+func macFromRequest(r *http.Request, key string) (hash.Hash, error) {
+	// Как и при шифровании, слабые ключи DES отклоняются
+	parity, err := parityFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := myDes.ValidateKey([]byte(key), parity); err != nil {
+		return nil, err
+	}
+
+	padding := mac.PaddingMethod1
+	switch p := r.FormValue("macPadding"); p {
+	case "", "method1":
+	case "method2":
+		padding = mac.PaddingMethod2
+	default:
+		return nil, formErrorf("unknown MAC padding %q", p)
+	}
+
+	macAlgorithm := r.FormValue("macAlgorithm")
+	if macAlgorithm == "retail" {
+		return mac.NewRetailMAC([]byte(key), padding)
+	}
+
+	algorithm, err := algorithmFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	var block cipher.Block
+	if algorithm == myDes.AlgorithmTripleDES {
+		block, err = myDes.NewTripleDES([]byte(key))
+	} else {
+		block, err = myDes.NewCipher([]byte(key))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch macAlgorithm {
+	case "", "cmac":
+		return mac.NewCMAC(block)
+	case "cbcmac":
+		return mac.NewCBCMAC(block, padding)
+	default:
+		return nil, formErrorf("unknown MAC algorithm %q", macAlgorithm)
+	}
+}
+
+// MAC вычисляет код аутентичности загруженного файла. Если в форме передан код (поле tag),
+// он проверяется; допускаются коды, усеченные до mac.MinTagSize байт. Файл читается из запроса потоком
+func (s *Service) MAC(w http.ResponseWriter, r *http.Request) {
+	file, err := uploadFromRequest(r)
+	if err != nil || file == nil {
+		log.Println(err)
+		renderError(w, errUpload)
+		return
+	}
+	key, err := keyFromRequest(r)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+	h, err := macFromRequest(r, key)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	if _, err := io.Copy(h, file); err != nil {
+		log.Println(err)
+		renderError(w, errUpload)
+		return
+	}
+	sum := h.Sum(nil)
+
+	tag := strings.TrimSpace(r.FormValue("tag"))
+	if tag == "" {
+		fmt.Fprintln(w, strings.ToUpper(hex.EncodeToString(sum)))
+		return
+	}
+
+	expected, err := hex.DecodeString(tag)
+	if err != nil {
+		renderError(w, formErrorf("tag is not a valid hex string"))
+		return
+	}
+	if !mac.Equal(sum, expected) {
+		renderError(w, formErrorf("MAC verification failed: the file was modified or the key is wrong"))
+		return
+	}
+	fmt.Fprintln(w, "MAC is valid")
+}
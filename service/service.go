@@ -2,15 +2,26 @@ package service
 
 import (
 	"IB3/myDes"
+	"bufio"
 	"encoding/hex"
+	"fmt"
 	"github.com/gorilla/mux"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
+// legacyIV - постоянный вектор инициализации старого шестнадцатеричного формата.
+// Он нужен только для расшифровки таких файлов: контейнеры хранят свой случайный IV в заголовке
+const legacyIV = "01234567"
+
 // Service - структура, представляющая веб-сервис
 type Service struct{}
 
@@ -30,6 +41,12 @@ func (s *Service) GetHandler() http.Handler {
 	router.HandleFunc("/home/shifr", s.Encode).Methods(http.MethodPost)
 	router.HandleFunc("/home/unshifr", s.Decode).Methods(http.MethodPost)
 	router.HandleFunc("/home/download", s.Download).Methods(http.MethodGet)
+	router.HandleFunc("/home/mac", s.MAC).Methods(http.MethodPost)
+	// Страницы исследования шифра принимают ключ только в теле POST (см. postFormOnly), по GET показывается форма
+	router.HandleFunc("/home/trace", s.Trace).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/home/keyschedule", s.KeySchedule).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/home/avalanche", s.Avalanche).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/home/linear", s.Linear).Methods(http.MethodGet, http.MethodPost)
 
 	// Возвращаем роутер в качестве обработчика запросов
 	return router
@@ -45,109 +62,410 @@ func (s *Service) About(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, "templates/about.html")
 }
 
-// Decode обрабатывает запрос на дешифрацию текста или файла с использованием DES
-func (s *Service) Decode(w http.ResponseWriter, r *http.Request) {
+// algorithmFromRequest определяет алгоритм шифрования, выбранный в форме (по умолчанию DES)
+func algorithmFromRequest(r *http.Request) (myDes.Algorithm, error) {
+	switch algorithm := r.FormValue("algorithm"); algorithm {
+	case "", "des":
+		return myDes.AlgorithmDES, nil
+	case "3des":
+		return myDes.AlgorithmTripleDES, nil
+	default:
+		return 0, formErrorf("unknown algorithm %q", algorithm)
+	}
+}
 
-	des := myDes.NewMyDES("01234567")
-	file, handler, err := r.FormFile("file")
+// modeFromRequest определяет режим работы шифра, выбранный в форме (по умолчанию CBC)
+func modeFromRequest(r *http.Request) (myDes.Mode, error) {
+	switch mode := r.FormValue("mode"); mode {
+	case "", "cbc":
+		return myDes.ModeCBC, nil
+	case "ecb":
+		return myDes.ModeECB, nil
+	case "cfb8":
+		return myDes.ModeCFB8, nil
+	case "cfb64":
+		return myDes.ModeCFB64, nil
+	case "ofb":
+		return myDes.ModeOFB, nil
+	case "ctr":
+		return myDes.ModeCTR, nil
+	default:
+		return 0, formErrorf("unknown mode %q", mode)
+	}
+}
+
+// paddingFromRequest определяет схему дополнения, выбранную в форме (по умолчанию PKCS#7)
+func paddingFromRequest(r *http.Request) (myDes.Padding, error) {
+	switch padding := r.FormValue("padding"); padding {
+	case "", "pkcs7":
+		return myDes.PaddingPKCS7, nil
+	case "iso7816":
+		return myDes.PaddingISO7816, nil
+	case "x923":
+		return myDes.PaddingANSIX923, nil
+	case "zero":
+		return myDes.PaddingZero, nil
+	default:
+		return 0, formErrorf("unknown padding %q", padding)
+	}
+}
+
+// kdfFromRequest определяет функцию выработки ключа: она используется, только если вместо ключа введен пароль
+func kdfFromRequest(r *http.Request) (myDes.KDFParams, error) {
+	if r.FormValue("keyFormat") != "passphrase" {
+		return myDes.KDFParams{}, nil
+	}
+
+	switch kdf := r.FormValue("kdf"); kdf {
+	case "", "pbkdf2":
+		iterations := uint64(myDes.DefaultPBKDF2Iterations)
+		if value := r.FormValue("iterations"); value != "" {
+			var err error
+			if iterations, err = strconv.ParseUint(value, 10, 32); err != nil || iterations == 0 {
+				return myDes.KDFParams{}, formErrorf("invalid iteration count %q", value)
+			}
+		}
+		return myDes.PBKDF2(uint32(iterations)), nil
+	case "scrypt":
+		return myDes.Scrypt(myDes.DefaultScryptN, myDes.DefaultScryptR, myDes.DefaultScryptP), nil
+	default:
+		return myDes.KDFParams{}, formErrorf("unknown key derivation function %q", kdf)
+	}
+}
+
+// postFormOnly оставляет в r.Form только поля тела запроса POST, чтобы страницы с ключом не читали его из адреса:
+// там ключ попадает в историю браузера, журналы сервера и заголовок Referer. Для GET форма пустая,
+// и страница показывается без результата
+func postFormOnly(r *http.Request) error {
+	if r.Method != http.MethodPost {
+		r.Form, r.PostForm = url.Values{}, url.Values{}
+		return nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return formErrorf("error parsing form: %v", err)
+	}
+	r.Form = r.PostForm
+	return nil
+}
+
+// legacyFromRequest сообщает, разрешена ли в форме расшифровка старого шестнадцатеричного формата
+func legacyFromRequest(r *http.Request) bool {
+	return r.FormValue("legacy") != ""
+}
+
+// desFromRequest создает экземпляр MyDES с алгоритмом, режимом, дополнением и функцией выработки ключа,
+// выбранными в форме
+func desFromRequest(r *http.Request) (*myDes.MyDES, error) {
+	algorithm, err := algorithmFromRequest(r)
 	if err != nil {
-		log.Println(err)
-		http.Error(w, "Error uploading file", http.StatusBadRequest)
-		return
+		return nil, err
+	}
+	mode, err := modeFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	padding, err := paddingFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	// Старые шестнадцатеричные файлы всегда дополнялись нулевыми байтами
+	if legacyFromRequest(r) {
+		padding = myDes.PaddingZero
+	}
+	kdf, err := kdfFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	parity, err := parityFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := variantFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	// Контейнеры всегда защищаются кодом аутентичности, а контейнеры без него не расшифровываются.
+	// Слабые ключи DES отклоняются
+	return myDes.NewMyDES(legacyIV, myDes.WithAlgorithm(algorithm), myDes.WithMode(mode), myDes.WithPadding(padding),
+		myDes.WithKDF(kdf), myDes.WithMAC(), myDes.WithKeyValidation(parity), myDes.WithVariant(variant)), nil
+}
+
+// roundsFromRequest читает число раундов из формы (пустое поле - 16 раундов стандарта)
+func roundsFromRequest(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.FormValue("rounds"))
+	if value == "" {
+		return 0, nil
+	}
+	rounds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, formErrorf("rounds must be a number: %q", value)
+	}
+	if rounds < 1 || rounds > myDes.MaxRounds {
+		return 0, myDes.RoundsError(rounds)
+	}
+	return rounds, nil
+}
+
+// variantName - допустимое название варианта таблиц: оно становится именем файла в каталоге tables
+var variantName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// variantFromRequest загружает вариант DES, выбранный в форме, из файла tables/<название>.json.
+// Для стандартного DES возвращается nil
+func variantFromRequest(r *http.Request) (*myDes.Variant, error) {
+	name := r.FormValue("variant")
+	if name == "" || name == "standard" {
+		return nil, nil
+	}
+	if !variantName.MatchString(name) {
+		return nil, formErrorf("unknown DES variant %q", name)
+	}
+
+	file, err := os.Open(filepath.Join("tables", name+".json"))
+	if err != nil {
+		return nil, formErrorf("unknown DES variant %q", name)
 	}
 	defer file.Close()
-	fileBytes, err := io.ReadAll(file)
+	return myDes.LoadVariant(file)
+}
+
+// parityFromRequest определяет, нужно ли требовать нечетную четность байтов ключа (по умолчанию не нужно)
+func parityFromRequest(r *http.Request) (myDes.Parity, error) {
+	switch parity := r.FormValue("parity"); parity {
+	case "", "ignore":
+		return myDes.ParityIgnore, nil
+	case "enforce":
+		return myDes.ParityEnforce, nil
+	default:
+		return 0, formErrorf("unknown parity check %q", parity)
+	}
+}
+
+// weakKeyMessage объясняет пользователю, почему ключ отклонен
+func weakKeyMessage(err *myDes.WeakKeyError) string {
+	return fmt.Sprintf("The key was refused (part %d of the key): %s. Choose a different key", err.Part+1, weakKeyReason(err))
+}
+
+// weakKeyReason описывает, чем плох слабый, полуслабый или возможно слабый ключ
+func weakKeyReason(err *myDes.WeakKeyError) string {
+	switch err.Class {
+	case myDes.KeyWeak:
+		return "it is a weak DES key: all 16 round keys are identical, so encrypting twice returns the original data"
+	case myDes.KeySemiWeak:
+		return "it is a semi-weak DES key: it has only 2 distinct round keys, and its partner key decrypts its ciphertexts"
+	default:
+		return "it is a possibly weak DES key: it produces only 4 distinct round keys instead of 16"
+	}
+}
+
+// keyFromRequest читает ключ из формы. Ключ вводится как текст, как шестнадцатеричная строка (формат hex)
+// или как пароль произвольной длины, из которого ключ вырабатывается при шифровании (формат passphrase).
+// Длина ключа проверяется при шифровании для выбранного алгоритма
+func keyFromRequest(r *http.Request) (string, error) {
+	key := r.FormValue("key")
+	if key == "" {
+		return "", formErrorf("key is required")
+	}
+
+	switch format := r.FormValue("keyFormat"); format {
+	case "", "text", "passphrase":
+		return key, nil
+	case "hex":
+		decoded, err := hex.DecodeString(strings.TrimSpace(key))
+		if err != nil {
+			return "", formErrorf("key is not a valid hex string: %v", err)
+		}
+		return string(decoded), nil
+	default:
+		return "", formErrorf("unknown key format %q", format)
+	}
+}
+
+// maxFieldSize - максимальный размер текстового поля формы. Файл в это ограничение не входит: он не читается в память
+const maxFieldSize = 10 << 20
+
+// uploadFromRequest читает текстовые поля multipart-формы в r.Form и возвращает часть с файлом (поле file),
+// не читая ее: файл обрабатывается потоком. Поля, идущие в форме после файла, не читаются, поэтому файл
+// должен быть последним полем формы. Если файл не передан, возвращается nil
+func uploadFromRequest(r *http.Request) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
 	if err != nil {
-		http.Error(w, "Error reading file", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
-	text := des.Decode(fileBytes, "Super_Secret_key")
-	processedFileName := "decode_" + handler.Filename
 
-	// Создание пути для сохранения обработанного файла
-	processedFilePath := filepath.Join(".", "processed_files", processedFileName)
+	r.Form = url.Values{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
 
+		if part.FileName() != "" {
+			if part.FormName() == "file" {
+				return part, nil
+			}
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, maxFieldSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(value) > maxFieldSize {
+			return nil, formErrorf("form field %q is too large", part.FormName())
+		}
+		r.Form.Add(part.FormName(), string(value))
+	}
+}
+
+// saveProcessedFile записывает результат обработки в processed_files/name. Результат сначала пишется во временный
+// файл и переименовывается только при успехе, поэтому при ошибке (например, нарушении целостности) частично
+// расшифрованные данные не становятся доступны для скачивания
+func saveProcessedFile(name string, write func(w io.Writer) error) error {
 	// Создание директории, если её нет
-	if _, err := os.Stat("processed_files"); os.IsNotExist(err) {
-		if err := os.Mkdir("processed_files", 0755); err != nil {
-			log.Println(err)
-			http.Error(w, "Error creating processed files directory", http.StatusInternalServerError)
-			return
+	if err := os.MkdirAll("processed_files", 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("processed_files", ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp создает файл с правами 0600, обработанные файлы создавались с правами 0644
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(".", "processed_files", name))
+}
+
+// decodeFile расшифровывает загруженный файл и пишет открытый текст в w. Контейнер myDes расшифровывается потоком
+// с параметрами из своего заголовка. Файл в старом шестнадцатеричном формате не защищен кодом аутентичности,
+// поэтому он расшифровывается с параметрами формы, только если legacy разрешает старый формат явно.
+// Иначе возвращается myDes.ErrNotContainer: подмена контейнера измененным старым файлом не проходит проверку
+func decodeFile(des *myDes.MyDES, file io.Reader, key string, legacy bool, w io.Writer) error {
+	src := bufio.NewReader(file)
+	if head, _ := src.Peek(len(myDes.ContainerMagic)); myDes.IsContainer(head) {
+		plain, err := des.NewDecrypter(src, key)
+		if err != nil {
+			return err
 		}
+		_, err = io.Copy(w, plain)
+		return err
+	}
+
+	if !legacy {
+		return myDes.ErrNotContainer
 	}
 
-	// Запись результата в обработанный файл
-	processedFile, err := os.Create(processedFilePath)
+	// Старый формат - шестнадцатеричный текст, который разбирается целиком.
+	// Такие файлы шифровались ключом, дополненным или обрезанным до нужной длины, поэтому длина не проверяется
+	fileBytes, err := io.ReadAll(src)
 	if err != nil {
+		return err
+	}
+	text, err := des.Decode(fileBytes, key)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, text)
+	return err
+}
+
+// Decode обрабатывает запрос на дешифрацию файла с использованием DES. Файл читается из запроса потоком
+func (s *Service) Decode(w http.ResponseWriter, r *http.Request) {
+	file, err := uploadFromRequest(r)
+	if err != nil || file == nil {
 		log.Println(err)
-		http.Error(w, "Error saving processed file", http.StatusInternalServerError)
+		renderError(w, errUpload)
+		return
+	}
+	des, err := desFromRequest(r)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+	key, err := keyFromRequest(r)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+
+	processedFileName := "decode_" + file.FileName()
+	err = saveProcessedFile(processedFileName, func(out io.Writer) error {
+		return decodeFile(des, file, key, legacyFromRequest(r), out)
+	})
+	if err != nil {
+		renderError(w, err)
 		return
 	}
-	//processedFile.Write([]byte(text))
-	processedFile.Write([]byte(text))
-	defer processedFile.Close()
 
 	// Перенаправление на страницу скачивания
 	http.Redirect(w, r, "/home/download?filename="+processedFileName, http.StatusSeeOther)
 }
 
-// Encode обрабатывает запрос на шифрацию файла с использованием DES
+// Encode обрабатывает запрос на шифрацию текста или файла с использованием DES. Файл шифруется потоком
+// прямо из запроса в выходной файл
 func (s *Service) Encode(w http.ResponseWriter, r *http.Request) {
-	var processedFileName string
-	var shifrText string
-	des := myDes.NewMyDES("01234567")
-	text := r.FormValue("text")
+	file, err := uploadFromRequest(r)
+	if err != nil {
+		log.Println(err)
+		renderError(w, errUpload)
+		return
+	}
+	des, err := desFromRequest(r)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
+	key, err := keyFromRequest(r)
+	if err != nil {
+		renderError(w, err)
+		return
+	}
 
-	// Если текст передан в запросе
-	if text != "" {
+	var (
+		processedFileName string
+		src               io.Reader
+	)
+	if text := r.FormValue("text"); text != "" {
+		// Если текст передан в запросе
 		log.Println(text)
-		shifrText = des.Encode(text, "Super_Secret_key")
-		log.Println("Зашифрованный текст", shifrText)
 		processedFileName = "encode_" + text + ".txt"
-
-	} else {
+		src = strings.NewReader(text)
+	} else if file != nil {
 		// Если файл передан в запросе
-		file, handler, err := r.FormFile("file")
-		if err != nil {
-			log.Println(err)
-			http.Error(w, "Error uploading file", http.StatusBadRequest)
-			return
-		}
-		defer file.Close()
+		processedFileName = "encode_" + file.FileName()
+		src = file
+	} else {
+		renderError(w, errUpload)
+		return
+	}
 
-		// Чтение данных файла
-		fileBytes, err := io.ReadAll(file)
+	err = saveProcessedFile(processedFileName, func(out io.Writer) error {
+		encrypter, err := des.NewEncrypter(out, key)
 		if err != nil {
-			http.Error(w, "Error reading file", http.StatusInternalServerError)
-			return
+			return err
 		}
-		text = string(fileBytes)
-		shifrText = des.Encode(string(fileBytes), "key")
-		processedFileName = "encode_" + handler.Filename
-	}
-
-	processedFilePath := filepath.Join(".", "processed_files", processedFileName)
-
-	// Создание директории, если её нет
-	if _, err := os.Stat("processed_files"); os.IsNotExist(err) {
-		if err := os.Mkdir("processed_files", 0755); err != nil {
-			log.Println(err)
-			http.Error(w, "Error creating processed files directory", http.StatusInternalServerError)
-			return
+		if _, err := io.Copy(encrypter, src); err != nil {
+			return err
 		}
-	}
-
-	// Запись результата в обработанный файл
-	processedFile, err := os.Create(processedFilePath)
+		return encrypter.Close()
+	})
 	if err != nil {
-		log.Println(err)
-		http.Error(w, "Error saving processed file", http.StatusInternalServerError)
+		renderError(w, err)
 		return
 	}
-	hexResult := hex.EncodeToString([]byte(shifrText))
-	log.Print(hexResult)
-	processedFile.Write([]byte(shifrText))
-	defer processedFile.Close()
 
 	// Перенаправление на страницу скачивания
 	http.Redirect(w, r, "/home/download?filename="+processedFileName, http.StatusSeeOther)
@@ -157,7 +475,7 @@ func (s *Service) Encode(w http.ResponseWriter, r *http.Request) {
 func (s *Service) Download(w http.ResponseWriter, r *http.Request) {
 	filename := r.URL.Query().Get("filename")
 	if filename == "" {
-		http.Error(w, "Invalid filename", http.StatusBadRequest)
+		renderError(w, formErrorf("Invalid filename"))
 		return
 	}
 
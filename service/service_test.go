@@ -0,0 +1,274 @@
+package service
+
+import (
+	"IB3/myDes"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// formFields возвращает имена полей формы с указанным action из templates/home.html в порядке их следования:
+// в этом порядке браузер отправляет поля multipart-формы
+func formFields(t *testing.T, action string) []string {
+	t.Helper()
+	page, err := os.ReadFile(filepath.Join("..", "templates", "home.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := regexp.MustCompile(`(?s)<form action="` + regexp.QuoteMeta(action) + `".*?</form>`).Find(page)
+	if form == nil {
+		t.Fatalf("form %s not found in home.html", action)
+	}
+	var names []string
+	for _, m := range regexp.MustCompile(`<(?:input|select|textarea)[^>]*\sname="([^"]+)"`).FindAllSubmatch(form, -1) {
+		names = append(names, string(m[1]))
+	}
+	return names
+}
+
+// postForm отправляет multipart-форму с полями в порядке fields. Поля без значения в values не отправляются,
+// как неотмеченные флажки; поле file передается как файл fileName с содержимым file
+func postForm(t *testing.T, action string, fields []string, values map[string]string, fileName string, file []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, name := range fields {
+		if name == "file" {
+			part, err := mw.CreateFormFile(name, fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write(file)
+			continue
+		}
+		if value, ok := values[name]; ok {
+			mw.WriteField(name, value)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, action, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	New().GetHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+// inTempDir переходит во временную директорию на время теста: обработанные файлы пишутся в processed_files
+// текущей директории
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// processedFile читает результат обработки, на скачивание которого перенаправил обработчик
+func processedFile(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusSeeOther, rec.Body)
+	}
+	name := strings.TrimPrefix(rec.Header().Get("Location"), "/home/download?filename=")
+	data, err := os.ReadFile(filepath.Join("processed_files", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestDecodeFormFieldOrder(t *testing.T) {
+	// Сервер не читает поля после файла, поэтому после него могут идти только поля,
+	// которые нужны лишь странице (флажок useText переключает поле ввода и сервером не читается)
+	for _, action := range []string{"/home/shifr", "/home/unshifr", "/home/mac"} {
+		fields := formFields(t, action)
+		after := false
+		for _, name := range fields {
+			if after && name != "useText" {
+				t.Errorf("%s: field %q follows the file and is never read", action, name)
+			}
+			after = after || name == "file"
+		}
+	}
+
+	fields := formFields(t, "/home/unshifr")
+	inTempDir(t)
+	const text, key = "Legacy file uploaded with the real form", "Secret_8"
+	legacy, err := myDes.NewMyDES(legacyIV, myDes.WithPadding(myDes.PaddingZero)).Encode(text, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Значения выбраны так, как их отправляет браузер по умолчанию: дополнение PKCS#7 в форме
+	// не должно мешать старому файлу, который всегда дополнялся нулевыми байтами
+	values := map[string]string{
+		"key":       key,
+		"keyFormat": "text",
+		"parity":    "ignore",
+		"algorithm": "des",
+		"variant":   "standard",
+		"mode":      "cbc",
+		"padding":   "pkcs7",
+		"legacy":    "on",
+	}
+	if got := processedFile(t, postForm(t, "/home/unshifr", fields, values, "legacy.txt", []byte(legacy))); got != text {
+		t.Errorf("decoded %q, want %q", got, text)
+	}
+
+	// Без флажка старый формат не принимается
+	delete(values, "legacy")
+	if rec := postForm(t, "/home/unshifr", fields, values, "legacy.txt", []byte(legacy)); rec.Code != http.StatusBadRequest {
+		t.Errorf("without legacy: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// legacyUpload - файл, зашифрованный исходной реализацией до появления контейнеров: текст
+// "Legacy upload, old hex format!" на ключе "Secret_8" в режиме CBC с IV "01234567"
+const legacyUpload = "0x7C013EDD4456FFE40x35FAEB01704CD6680x48A12FB8BD0D1F1F0x317F75246DC27731"
+
+func TestDecodeLegacyUpload(t *testing.T) {
+	fields := formFields(t, "/home/unshifr")
+	inTempDir(t)
+
+	values := map[string]string{"key": "Secret_8", "legacy": "on"}
+	if got, want := processedFile(t, postForm(t, "/home/unshifr", fields, values, "old.txt", []byte(legacyUpload))), "Legacy upload, old hex format!"; got != want {
+		t.Errorf("decoded %q, want %q", got, want)
+	}
+}
+
+// inRepoRoot переходит в корень репозитория на время теста: страницы читают шаблоны из templates
+func inRepoRoot(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(".."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// serve выполняет запрос к обработчику сервиса; form, если задана, передается в теле POST
+func serve(method, target string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	rec := httptest.NewRecorder()
+	New().GetHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestKeyPagesPostOnly(t *testing.T) {
+	inRepoRoot(t)
+	form := url.Values{"key": {"Secret_8"}, "block": {"ABCDEFGH"}, "rounds": {"3"}, "samples": {"10"}}
+
+	for _, page := range []string{"/home/trace", "/home/keyschedule", "/home/avalanche", "/home/linear"} {
+		// Ключ в адресе не используется: страница показывает пустую форму
+		rec := serve(http.MethodGet, page+"?"+form.Encode(), nil)
+		if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "Secret_8") {
+			t.Errorf("GET %s: status %d, key used from the query", page, rec.Code)
+		}
+
+		rec = serve(http.MethodPost, page, form)
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `value="Secret_8"`) {
+			t.Errorf("POST %s: status %d: %s", page, rec.Code, rec.Body)
+		}
+	}
+
+	// Выгрузки тоже принимают ключ только в теле запроса
+	csv := url.Values{"key": {"Secret_8"}, "block": {"ABCDEFGH"}, "format": {"csv"}}
+	if rec := serve(http.MethodPost, "/home/avalanche", csv); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("POST CSV: status %d, %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec := serve(http.MethodGet, "/home/avalanche?"+csv.Encode(), nil); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") == "text/csv" {
+		t.Errorf("GET CSV: status %d, the report was built from the query", rec.Code)
+	}
+	schedule := url.Values{"key": {"Secret_8"}, "format": {"json"}}
+	if rec := serve(http.MethodPost, "/home/keyschedule", schedule); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"parts"`) {
+		t.Errorf("POST JSON: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestLinearLimits(t *testing.T) {
+	inRepoRoot(t)
+	form := url.Values{"key": {"Secret_8"}, "rounds": {"3"}, "samples": {"10"}}
+
+	for name, value := range map[string]string{"rounds": "9", "samples": "22"} {
+		tooMany := url.Values{"key": {"Secret_8"}, "rounds": {"3"}, "samples": {"10"}}
+		tooMany.Set(name, value)
+		if rec := serve(http.MethodPost, "/home/linear", tooMany); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s=%s: status %d", name, value, rec.Code)
+		}
+	}
+
+	// Анализатор для тех же таблиц не строится заново
+	if linearAnalyzer(nil) != linearAnalyzer(nil) {
+		t.Error("the analyzer is not cached")
+	}
+
+	// Пока идет эксперимент, следующий отклоняется
+	linearExperiment.Lock()
+	rec := serve(http.MethodPost, "/home/linear", form)
+	linearExperiment.Unlock()
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("concurrent experiment: status %d", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "/home/linear", form); rec.Code != http.StatusOK {
+		t.Errorf("status %d after the experiment finished", rec.Code)
+	}
+}
+
+func TestKeyScheduleVariant(t *testing.T) {
+	inTempDir(t)
+
+	// Вариант с другими сдвигами половин ключа меняет все подключи, кроме последнего
+	tables := myDes.StandardTables()
+	tables.Name = "shifted"
+	tables.Shifts[0], tables.Shifts[2] = 2, 1
+	data, err := json.Marshal(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir("tables", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("tables", "shifted.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	variant, err := myDes.NewVariant(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := variant.TraceKeySchedule([]byte("Secret_8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serve(http.MethodPost, "/home/keyschedule", url.Values{"key": {"Secret_8"}, "variant": {"shifted"}, "format": {"json"}})
+	var got struct {
+		Parts []keyScheduleView `json:"parts"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("status %d: %v", rec.Code, err)
+	}
+	if len(got.Parts) != 1 || got.Parts[0].Rounds[0].SubKey.Hex != hexBits(want.Rounds[0].SubKey, 48) {
+		t.Errorf("the schedule does not use the selected tables: %+v", got.Parts)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"IB3/myDes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// traceRound - строка таблицы трассировки: значения раунда, подготовленные для вывода
+type traceRound struct {
+	Pass      int
+	Round     int
+	Left      string
+	Right     string
+	SubKey    string
+	Expanded  string
+	XORed     string
+	SBoxes    []string
+	PBox      string
+	NextLeft  string
+	NextRight string
+}
+
+// tracePage - данные шаблона templates/trace.html
+type tracePage struct {
+	Form      url.Values   // Значения формы, чтобы показать их снова
+	Error     string       // Ошибка во входных данных
+	Warning   string       // Предупреждение о слабом ключе: трассировка с ним разрешена, он интересен для изучения
+	Algorithm string       // Название алгоритма
+	Decrypt   bool         // Выполнялась расшифровка
+	Input     string       // Входной блок
+	IP        string       // Результат начальной перестановки
+	Rounds    []traceRound // Раунды
+	PreOutput string       // R || L последнего раунда
+	Output    string       // Результат конечной перестановки
+}
+
+// hexBits возвращает значение из bits бит в шестнадцатеричном виде
+func hexBits(v uint64, bits int) string {
+	return fmt.Sprintf("%0*X", bits/4, v)
+}
+
+// binBits возвращает значение из bits бит в двоичном виде, разбитое пробелами на группы по group бит
+func binBits(v uint64, bits, group int) string {
+	s := fmt.Sprintf("%0*b", bits, v)
+	var b strings.Builder
+	for i := 0; i < len(s); i += group {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s[i : i+group])
+	}
+	return b.String()
+}
+
+// blockFromRequest читает блок для трассировки: 8 символов текста (короткий текст дополняется нулями)
+// или 16 шестнадцатеричных цифр (формат hex)
+func blockFromRequest(r *http.Request) ([]byte, error) {
+	block := r.FormValue("block")
+	switch format := r.FormValue("blockFormat"); format {
+	case "", "text":
+		if len(block) > myDes.BlockSize {
+			return nil, formErrorf("block must be at most %d bytes of text", myDes.BlockSize)
+		}
+		padded := make([]byte, myDes.BlockSize)
+		copy(padded, block)
+		return padded, nil
+	case "hex":
+		decoded, err := hex.DecodeString(strings.TrimSpace(block))
+		if err != nil || len(decoded) != myDes.BlockSize {
+			return nil, formErrorf("block must be exactly %d hex digits", 2*myDes.BlockSize)
+		}
+		return decoded, nil
+	default:
+		return nil, formErrorf("unknown block format %q", format)
+	}
+}
+
+// traceFromRequest выполняет трассировку по параметрам формы и заполняет страницу
+func traceFromRequest(r *http.Request, page *tracePage) error {
+	key, err := keyFromRequest(r)
+	if err != nil {
+		return err
+	}
+	algorithm, err := algorithmFromRequest(r)
+	if err != nil {
+		return err
+	}
+	block, err := blockFromRequest(r)
+	if err != nil {
+		return err
+	}
+	variant, err := variantFromRequest(r)
+	if err != nil {
+		return err
+	}
+	rounds, err := roundsFromRequest(r)
+	if err != nil {
+		return err
+	}
+	isDecode := r.FormValue("direction") == "decrypt"
+
+	d := myDes.NewMyDES(legacyIV, myDes.WithAlgorithm(algorithm), myDes.WithVariant(variant), myDes.WithRounds(rounds))
+	trace, err := d.TraceBlock(block, key, isDecode)
+	if err != nil {
+		return err
+	}
+
+	// Слабые ключи не отклоняются, а отмечаются: на них хорошо видно устройство расписания подключей
+	var weakKeyErr *myDes.WeakKeyError
+	if _, err := variant.ValidateKey([]byte(key), myDes.ParityIgnore); errors.As(err, &weakKeyErr) {
+		page.Warning = fmt.Sprintf("Part %d of the key: %s", weakKeyErr.Part+1, weakKeyReason(weakKeyErr))
+	}
+
+	page.Algorithm = "DES"
+	if trace.Algorithm == myDes.AlgorithmTripleDES {
+		page.Algorithm = "Triple DES"
+	}
+	page.Decrypt = trace.Decrypt
+	page.Input = hexBits(trace.Input, 64)
+	page.IP = hexBits(trace.InitialPermutation, 64)
+	page.PreOutput = hexBits(trace.PreOutput, 64)
+	page.Output = hexBits(trace.Output, 64)
+
+	for _, round := range trace.Rounds {
+		row := traceRound{
+			Pass:      round.Pass,
+			Round:     round.Round,
+			Left:      hexBits(uint64(round.Left), 32),
+			Right:     hexBits(uint64(round.Right), 32),
+			SubKey:    binBits(round.SubKey, 48, 6),
+			Expanded:  binBits(round.Expanded, 48, 6),
+			XORed:     binBits(round.XORed, 48, 6),
+			PBox:      hexBits(uint64(round.PBox), 32),
+			NextLeft:  hexBits(uint64(round.NextLeft), 32),
+			NextRight: hexBits(uint64(round.NextRight), 32),
+		}
+		for i := range round.SBoxIn {
+			row.SBoxes = append(row.SBoxes, fmt.Sprintf("S%d: %06b → %04b", i+1, round.SBoxIn[i], round.SBoxOut[i]))
+		}
+		page.Rounds = append(page.Rounds, row)
+	}
+	return nil
+}
+
+// Trace обрабатывает запрос на страницу трассировки: без ключа показывается только форма,
+// с ключом и блоком (форма отправляется методом POST) - таблица всех шагов шифрования или расшифровки блока
+func (s *Service) Trace(w http.ResponseWriter, r *http.Request) {
+	if err := postFormOnly(r); err != nil {
+		renderError(w, err)
+		return
+	}
+
+	page := tracePage{Form: r.Form}
+	status := http.StatusOK
+	if r.FormValue("key") != "" {
+		if err := traceFromRequest(r, &page); err != nil {
+			status, page.Error = describeError(err)
+			page.Rounds = nil
+		}
+	}
+	renderPage(w, "trace.html", status, page)
+}